@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"pr-reviewer/internal/service"
+)
+
+// runOutboxDispatcher drains the outbox every interval until ctx is done.
+func runOutboxDispatcher(ctx context.Context, svc *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := svc.DispatchOutbox(ctx)
+			if err != nil {
+				log.Printf("outbox dispatch: %v", err)
+			}
+			if err != nil || n < service.OutboxBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
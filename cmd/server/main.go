@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/github"
 	"pr-reviewer/internal/handlers"
 	"pr-reviewer/internal/migrations"
+	"pr-reviewer/internal/notify"
 	"pr-reviewer/internal/repo"
 	"pr-reviewer/internal/service"
 	"pr-reviewer/pkg/config"
@@ -35,7 +37,24 @@ func main() {
 
 	repository := repo.NewRepo(pool)
 	svc := service.NewService(repository)
+	if err := svc.SetDefaultStrategy(cfg.ReviewerStrategy); err != nil {
+		log.Fatalf("config error: %v", err)
+	}
+	if cfg.Seed != nil {
+		svc.SetSeed(*cfg.Seed)
+	}
+	svc.SetDeterministic(cfg.DeterministicAssignment)
+	if cfg.GitHubToken != "" {
+		svc.SetReviewRequester(api.Github, github.NewClient(cfg.GitHubAPIURL, cfg.GitHubToken))
+		go svc.RunReviewerSync(ctx, cfg.ReviewerSyncInterval)
+	}
+	svc.SetWebhookSender(notify.NewClient(cfg.WebhookTimeout))
+	go svc.RunWebhookDelivery(ctx, cfg.WebhookDeliveryInterval)
+	go runOutboxDispatcher(ctx, svc, cfg.OutboxInterval)
+	go svc.RunScheduledHandoffs(ctx, cfg.HandoffInterval)
 	apiServer := handlers.NewServer(svc)
+	apiServer.SetGitHubWebhookSecret(cfg.GitHubWebhookSecret)
+	apiServer.SetGitLabWebhookToken(cfg.GitLabWebhookToken)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
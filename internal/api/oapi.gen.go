@@ -14,26 +14,106 @@ import (
 
 // Defines values for ErrorResponseErrorCode.
 const (
-	NOCANDIDATE ErrorResponseErrorCode = "NO_CANDIDATE"
-	NOTASSIGNED ErrorResponseErrorCode = "NOT_ASSIGNED"
-	NOTFOUND    ErrorResponseErrorCode = "NOT_FOUND"
-	PREXISTS    ErrorResponseErrorCode = "PR_EXISTS"
-	PRMERGED    ErrorResponseErrorCode = "PR_MERGED"
-	TEAMEXISTS  ErrorResponseErrorCode = "TEAM_EXISTS"
+	BADREQUEST      ErrorResponseErrorCode = "BAD_REQUEST"
+	NOCANDIDATE     ErrorResponseErrorCode = "NO_CANDIDATE"
+	NOTAPPROVED     ErrorResponseErrorCode = "NOT_APPROVED"
+	NOTASSIGNED     ErrorResponseErrorCode = "NOT_ASSIGNED"
+	NOTFOUND        ErrorResponseErrorCode = "NOT_FOUND"
+	PRCLOSED        ErrorResponseErrorCode = "PR_CLOSED"
+	PREXISTS        ErrorResponseErrorCode = "PR_EXISTS"
+	PRMERGED        ErrorResponseErrorCode = "PR_MERGED"
+	TEAMEXISTS      ErrorResponseErrorCode = "TEAM_EXISTS"
+	TEAMHASOPENPRS  ErrorResponseErrorCode = "TEAM_HAS_OPEN_PRS"
+	UNAUTHORIZED    ErrorResponseErrorCode = "UNAUTHORIZED"
+	USERINOTHERTEAM ErrorResponseErrorCode = "USER_IN_OTHER_TEAM"
+)
+
+// Defines values for GitProvider.
+const (
+	Github GitProvider = "github"
+	Gitlab GitProvider = "gitlab"
 )
 
 // Defines values for PullRequestStatus.
 const (
+	PullRequestStatusCLOSED PullRequestStatus = "CLOSED"
 	PullRequestStatusMERGED PullRequestStatus = "MERGED"
 	PullRequestStatusOPEN   PullRequestStatus = "OPEN"
 )
 
 // Defines values for PullRequestShortStatus.
 const (
+	PullRequestShortStatusCLOSED PullRequestShortStatus = "CLOSED"
 	PullRequestShortStatusMERGED PullRequestShortStatus = "MERGED"
 	PullRequestShortStatusOPEN   PullRequestShortStatus = "OPEN"
 )
 
+// Defines values for ReviewDecision.
+const (
+	APPROVED         ReviewDecision = "APPROVED"
+	CHANGESREQUESTED ReviewDecision = "CHANGES_REQUESTED"
+	COMMENTED        ReviewDecision = "COMMENTED"
+)
+
+// Defines values for ReviewerStrategy.
+const (
+	Diverse     ReviewerStrategy = "diverse"
+	LeastLoaded ReviewerStrategy = "least_loaded"
+	Random      ReviewerStrategy = "random"
+	RoundRobin  ReviewerStrategy = "round_robin"
+	SkillMatch  ReviewerStrategy = "skill_match"
+	Weighted    ReviewerStrategy = "weighted"
+)
+
+// Defines values for UserLevel.
+const (
+	Junior UserLevel = "junior"
+	Mid    UserLevel = "mid"
+	Senior UserLevel = "senior"
+)
+
+// Defines values for WebhookDeliveryStatus.
+const (
+	Dead      WebhookDeliveryStatus = "dead"
+	Delivered WebhookDeliveryStatus = "delivered"
+	Pending   WebhookDeliveryStatus = "pending"
+)
+
+// Defines values for WebhookEventType.
+const (
+	PullRequestClosed             WebhookEventType = "pull_request.closed"
+	PullRequestCreated            WebhookEventType = "pull_request.created"
+	PullRequestMerged             WebhookEventType = "pull_request.merged"
+	PullRequestReadyForReview     WebhookEventType = "pull_request.ready_for_review"
+	PullRequestReopened           WebhookEventType = "pull_request.reopened"
+	PullRequestReviewSubmitted    WebhookEventType = "pull_request.review_submitted"
+	PullRequestReviewerReassigned WebhookEventType = "pull_request.reviewer_reassigned"
+)
+
+// Defines values for WebhookResultResult.
+const (
+	Applied WebhookResultResult = "applied"
+	Ignored WebhookResultResult = "ignored"
+)
+
+// CodeOwners defines model for CodeOwners.
+type CodeOwners struct {
+	// Content Файл владельцев в синтаксисе CODEOWNERS
+	Content string `json:"content"`
+
+	// Rules Разобранные правила; при совпадении нескольких действует последнее
+	Rules     []CodeOwnersRule `json:"rules"`
+	TeamName  string           `json:"team_name"`
+	UpdatedAt *time.Time       `json:"updated_at"`
+}
+
+// CodeOwnersRule defines model for CodeOwnersRule.
+type CodeOwnersRule struct {
+	// Owners @user_id для пользователя, @team/team_name для команды; пустой список — файлы без владельца
+	Owners  []string `json:"owners"`
+	Pattern string   `json:"pattern"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error struct {
@@ -45,16 +125,45 @@ type ErrorResponse struct {
 // ErrorResponseErrorCode defines model for ErrorResponse.Error.Code.
 type ErrorResponseErrorCode string
 
+// ExternalLogin defines model for ExternalLogin.
+type ExternalLogin struct {
+	// Login Логин на Git-хостинге (без учёта регистра)
+	Login string `json:"login"`
+
+	// Provider Git-хостинг, присылающий вебхуки
+	Provider GitProvider `json:"provider"`
+	UserId   string      `json:"user_id"`
+}
+
+// GitProvider Git-хостинг, присылающий вебхуки
+type GitProvider string
+
 // PullRequest defines model for PullRequest.
 type PullRequest struct {
-	// AssignedReviewers user_id назначенных ревьюверов (0..2)
-	AssignedReviewers []string          `json:"assigned_reviewers"`
-	AuthorId          string            `json:"author_id"`
-	CreatedAt         *time.Time        `json:"createdAt"`
-	MergedAt          *time.Time        `json:"mergedAt"`
-	PullRequestId     string            `json:"pull_request_id"`
-	PullRequestName   string            `json:"pull_request_name"`
-	Status            PullRequestStatus `json:"status"`
+	// AssignedReviewers user_id назначенных ревьюверов (0..reviewers_count)
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	AuthorId          string   `json:"author_id"`
+
+	// ChangedPaths Изменённые файлы, по которым определяются владельцы кода
+	ChangedPaths *[]string  `json:"changed_paths,omitempty"`
+	ClosedAt     *time.Time `json:"closedAt"`
+	CreatedAt    *time.Time `json:"createdAt"`
+
+	// Draft Черновик — ревьюверы назначаются только после /pullRequest/markReady
+	Draft *bool `json:"draft,omitempty"`
+
+	// ForceMerged PR слит в обход политики одобрений
+	ForceMerged *bool `json:"force_merged,omitempty"`
+
+	// Labels Метки PR, сопоставляемые с навыками ревьюверов
+	Labels          *[]string  `json:"labels,omitempty"`
+	MergedAt        *time.Time `json:"mergedAt"`
+	PullRequestId   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+
+	// Reviewers Назначенные ревьюверы с их решениями, в порядке слотов
+	Reviewers *[]Reviewer       `json:"reviewers,omitempty"`
+	Status    PullRequestStatus `json:"status"`
 }
 
 // PullRequestStatus defines model for PullRequest.Status.
@@ -63,6 +172,7 @@ type PullRequestStatus string
 // PullRequestShort defines model for PullRequestShort.
 type PullRequestShort struct {
 	AuthorId        string                 `json:"author_id"`
+	Decision        *ReviewDecision        `json:"decision,omitempty"`
 	PullRequestId   string                 `json:"pull_request_id"`
 	PullRequestName string                 `json:"pull_request_name"`
 	Status          PullRequestShortStatus `json:"status"`
@@ -71,169 +181,1559 @@ type PullRequestShort struct {
 // PullRequestShortStatus defines model for PullRequestShort.Status.
 type PullRequestShortStatus string
 
+// ReassignmentReport defines model for ReassignmentReport.
+type ReassignmentReport struct {
+	Reassigned []ReviewHandoff `json:"reassigned"`
+
+	// Unassigned Открытые ревью, для которых не нашлось активной замены
+	Unassigned []ReviewHandoff `json:"unassigned"`
+}
+
+// ReviewDecision defines model for ReviewDecision.
+type ReviewDecision string
+
+// ReviewHandoff defines model for ReviewHandoff.
+type ReviewHandoff struct {
+	// NewReviewerId Отсутствует, если подходящего кандидата не нашлось
+	NewReviewerId *string `json:"new_reviewer_id,omitempty"`
+	OldReviewerId string  `json:"old_reviewer_id"`
+	PullRequestId string  `json:"pull_request_id"`
+}
+
+// Reviewer defines model for Reviewer.
+type Reviewer struct {
+	AssignedAt time.Time       `json:"assigned_at"`
+	DecidedAt  *time.Time      `json:"decided_at"`
+	Decision   *ReviewDecision `json:"decision,omitempty"`
+
+	// TeamName Команда, из которой назначен ревьювер (своя или запасная)
+	TeamName *string `json:"team_name,omitempty"`
+	UserId   string  `json:"user_id"`
+}
+
+// ReviewerStrategy Стратегия выбора ревьюверов:
+// - random — случайный выбор;
+// - round_robin — по очереди среди участников команды;
+// - least_loaded — в первую очередь участники с наименьшим числом открытых ревью;
+// - weighted — случайный выбор с весами из reviewer_weights;
+// - skill_match — в первую очередь участники, чьи навыки больше всего совпадают с метками PR, остальные случайно;
+// - diverse — случайный выбор, при котором реже выбираются ревьюверы последних diversity_window PR автора.
+type ReviewerStrategy string
+
 // Team defines model for Team.
 type Team struct {
 	Members  []TeamMember `json:"members"`
 	TeamName string       `json:"team_name"`
 }
 
-// TeamMember defines model for TeamMember.
-type TeamMember struct {
-	IsActive bool   `json:"is_active"`
-	UserId   string `json:"user_id"`
-	Username string `json:"username"`
-}
+// TeamMember При повторном добавлении существующего пользователя не переданные max_open_reviews, tags и level сохраняют прежние значения.
+type TeamMember struct {
+	IsActive bool `json:"is_active"`
+
+	// Level Уровень пользователя для политики наставничества (require_senior, junior_shadow)
+	Level *UserLevel `json:"level,omitempty"`
+
+	// MaxOpenReviews Сколько открытых ревью может быть у пользователя одновременно (null — лимит команды)
+	MaxOpenReviews *int `json:"max_open_reviews"`
+
+	// Tags Навыки пользователя (например, go, sql, infra) для стратегии skill_match
+	Tags     *[]string `json:"tags,omitempty"`
+	UserId   string    `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// TeamMove defines model for TeamMove.
+type TeamMove struct {
+	FromTeam *string   `json:"from_team"`
+	MovedAt  time.Time `json:"moved_at"`
+	ToTeam   string    `json:"to_team"`
+	UserId   string    `json:"user_id"`
+}
+
+// TeamSettings defines model for TeamSettings.
+type TeamSettings struct {
+	// BlockOnChangesRequested Запрещать merge, пока у PR есть решение CHANGES_REQUESTED
+	BlockOnChangesRequested *bool `json:"block_on_changes_requested,omitempty"`
+
+	// DiversityWindow Сколько последних PR автора учитывает стратегия diverse
+	DiversityWindow *int `json:"diversity_window,omitempty"`
+
+	// FallbackTeams Запасные команды по порядку, из которых добираются ревьюверы, если в команде не хватает кандидатов
+	FallbackTeams *[]string `json:"fallback_teams,omitempty"`
+
+	// JuniorShadow Резервировать один слот ревьювера для junior, который ревьюит вместе с senior
+	JuniorShadow *bool `json:"junior_shadow,omitempty"`
+
+	// MaxOpenReviews Лимит открытых ревью на участника по умолчанию (null — без лимита)
+	MaxOpenReviews *int `json:"max_open_reviews"`
+
+	// RequireSenior Среди ревьюверов PR должен быть хотя бы один senior; переназначение не может нарушить это правило
+	RequireSenior *bool `json:"require_senior,omitempty"`
+
+	// RequiredApprovals Сколько одобрений (APPROVED) нужно для merge
+	RequiredApprovals *int `json:"required_approvals,omitempty"`
+
+	// ReviewerStrategy Стратегия выбора ревьюверов:
+	// - random — случайный выбор;
+	// - round_robin — по очереди среди участников команды;
+	// - least_loaded — в первую очередь участники с наименьшим числом открытых ревью;
+	// - weighted — случайный выбор с весами из reviewer_weights;
+	// - skill_match — в первую очередь участники, чьи навыки больше всего совпадают с метками PR, остальные случайно;
+	// - diverse — случайный выбор, при котором реже выбираются ревьюверы последних diversity_window PR автора.
+	ReviewerStrategy ReviewerStrategy `json:"reviewer_strategy"`
+
+	// ReviewerWeights Веса участников для стратегии weighted (по умолчанию 1, 0 — не назначать)
+	ReviewerWeights *map[string]int `json:"reviewer_weights,omitempty"`
+
+	// ReviewersCount Сколько ревьюверов назначать на новый PR
+	ReviewersCount *int   `json:"reviewers_count,omitempty"`
+	TeamName       string `json:"team_name"`
+}
+
+// UnavailabilityWindow defines model for UnavailabilityWindow.
+type UnavailabilityWindow struct {
+	EndsAt time.Time `json:"ends_at"`
+
+	// HandoffAt Когда открытые ревью пользователя будут переназначены (за 24 часа до начала периода). Есть только у периодов с запланированным, но ещё не выполненным переназначением.
+	HandoffAt *time.Time `json:"handoff_at,omitempty"`
+	Id        int64      `json:"id"`
+	Reason    *string    `json:"reason,omitempty"`
+	StartsAt  time.Time  `json:"starts_at"`
+	UserId    string     `json:"user_id"`
+}
+
+// User defines model for User.
+type User struct {
+	IsActive bool `json:"is_active"`
+
+	// Level Уровень пользователя для политики наставничества (require_senior, junior_shadow)
+	Level          *UserLevel `json:"level,omitempty"`
+	MaxOpenReviews *int       `json:"max_open_reviews"`
+
+	// Tags Навыки пользователя
+	Tags     *[]string `json:"tags,omitempty"`
+	TeamName string    `json:"team_name"`
+	UserId   string    `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// UserLevel Уровень пользователя для политики наставничества (require_senior, junior_shadow)
+type UserLevel string
+
+// Warning defines model for Warning.
+type Warning struct {
+	// Code Код предупреждения (например, NO_CANDIDATE)
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempts    int              `json:"attempts"`
+	CreatedAt   time.Time        `json:"created_at"`
+	DeliveredAt *time.Time       `json:"delivered_at,omitempty"`
+	DeliveryId  int64            `json:"delivery_id"`
+	Event       WebhookEventType `json:"event"`
+	LastError   *string          `json:"last_error,omitempty"`
+
+	// NextAttemptAt Только для pending
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// Status dead — получатель отказал окончательно или попытки кончились; такую доставку можно повторить через /webhooks/subscriptions/redeliver
+	Status         WebhookDeliveryStatus `json:"status"`
+	SubscriptionId int64                 `json:"subscription_id"`
+}
+
+// WebhookDeliveryStatus dead — получатель отказал окончательно или попытки кончились; такую доставку можно повторить через /webhooks/subscriptions/redeliver
+type WebhookDeliveryStatus string
+
+// WebhookEvent Тело исходящего вебхука. Запрос подписывается HMAC-SHA256 секретом подписки в заголовке X-PR-Reviewer-Signature-256 (sha256=<hex>), тип события передаётся в X-PR-Reviewer-Event, номер доставки — в X-PR-Reviewer-Delivery.
+type WebhookEvent struct {
+	Event        WebhookEventType `json:"event"`
+	OccurredAt   time.Time        `json:"occurred_at"`
+	PullRequest  PullRequest      `json:"pull_request"`
+	Reassignment *ReviewHandoff   `json:"reassignment,omitempty"`
+	Review       *Reviewer        `json:"review,omitempty"`
+}
+
+// WebhookEventType defines model for WebhookEventType.
+type WebhookEventType string
+
+// WebhookResult defines model for WebhookResult.
+type WebhookResult struct {
+	Action *string `json:"action,omitempty"`
+
+	// Event Тип события Git-хостинга
+	Event         string       `json:"event"`
+	PullRequest   *PullRequest `json:"pull_request,omitempty"`
+	PullRequestId *string      `json:"pull_request_id,omitempty"`
+
+	// Reason Почему событие пропущено
+	Reason   *string             `json:"reason,omitempty"`
+	Result   WebhookResultResult `json:"result"`
+	Warnings *[]Warning          `json:"warnings,omitempty"`
+}
+
+// WebhookResultResult defines model for WebhookResult.Result.
+type WebhookResultResult string
+
+// WebhookSubscription defines model for WebhookSubscription.
+type WebhookSubscription struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// Events События, на которые подписан получатель; пусто — все
+	Events         []WebhookEventType `json:"events"`
+	SubscriptionId int64              `json:"subscription_id"`
+	Url            string             `json:"url"`
+}
+
+// TeamNameQuery defines model for TeamNameQuery.
+type TeamNameQuery = string
+
+// UserIdQuery defines model for UserIdQuery.
+type UserIdQuery = string
+
+// PostPullRequestCloseJSONBody defines parameters for PostPullRequestClose.
+type PostPullRequestCloseJSONBody struct {
+	PullRequestId string `json:"pull_request_id"`
+}
+
+// PostPullRequestCreateJSONBody defines parameters for PostPullRequestCreate.
+type PostPullRequestCreateJSONBody struct {
+	AuthorId string `json:"author_id"`
+
+	// ChangedPaths Изменённые файлы; хотя бы один ревьювер будет выбран из их владельцев по файлу CODEOWNERS команды
+	ChangedPaths *[]string `json:"changed_paths,omitempty"`
+
+	// Draft Создать черновик без назначения ревьюверов
+	Draft *bool `json:"draft,omitempty"`
+
+	// IgnoreCapacity Игнорировать лимиты открытых ревью (для срочных PR)
+	IgnoreCapacity *bool `json:"ignore_capacity,omitempty"`
+
+	// Labels Метки PR (например, sql, migrations) для стратегии skill_match
+	Labels          *[]string `json:"labels,omitempty"`
+	PullRequestId   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+
+	// ReviewersCount Переопределяет reviewers_count из настроек команды
+	ReviewersCount *int `json:"reviewers_count,omitempty"`
+}
+
+// PostPullRequestMarkReadyJSONBody defines parameters for PostPullRequestMarkReady.
+type PostPullRequestMarkReadyJSONBody struct {
+	PullRequestId string `json:"pull_request_id"`
+
+	// ReviewersCount Переопределяет reviewers_count из настроек команды
+	ReviewersCount *int `json:"reviewers_count,omitempty"`
+}
+
+// PostPullRequestMergeJSONBody defines parameters for PostPullRequestMerge.
+type PostPullRequestMergeJSONBody struct {
+	Force         *bool  `json:"force,omitempty"`
+	PullRequestId string `json:"pull_request_id"`
+}
+
+// PostPullRequestReassignJSONBody defines parameters for PostPullRequestReassign.
+type PostPullRequestReassignJSONBody struct {
+	OldUserId     string `json:"old_user_id"`
+	PullRequestId string `json:"pull_request_id"`
+}
+
+// PostPullRequestReopenJSONBody defines parameters for PostPullRequestReopen.
+type PostPullRequestReopenJSONBody struct {
+	PullRequestId string `json:"pull_request_id"`
+}
+
+// PostPullRequestReviewJSONBody defines parameters for PostPullRequestReview.
+type PostPullRequestReviewJSONBody struct {
+	Decision      ReviewDecision `json:"decision"`
+	PullRequestId string         `json:"pull_request_id"`
+	ReviewerId    string         `json:"reviewer_id"`
+}
+
+// PostTeamAddParams defines parameters for PostTeamAdd.
+type PostTeamAddParams struct {
+	// MoveMembers Разрешить перенос участников из других команд
+	MoveMembers *bool `form:"move_members,omitempty" json:"move_members,omitempty"`
+}
+
+// GetTeamCodeownersGetParams defines parameters for GetTeamCodeownersGet.
+type GetTeamCodeownersGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
+// PostTeamCodeownersSetJSONBody defines parameters for PostTeamCodeownersSet.
+type PostTeamCodeownersSetJSONBody struct {
+	Content  string `json:"content"`
+	TeamName string `json:"team_name"`
+}
+
+// PostTeamDeactivateUsersJSONBody defines parameters for PostTeamDeactivateUsers.
+type PostTeamDeactivateUsersJSONBody struct {
+	// AllExcept Деактивировать всех участников, кроме user_ids
+	AllExcept *bool    `json:"all_except,omitempty"`
+	TeamName  string   `json:"team_name"`
+	UserIds   []string `json:"user_ids"`
+}
+
+// PostTeamDeleteJSONBody defines parameters for PostTeamDelete.
+type PostTeamDeleteJSONBody struct {
+	TeamName string `json:"team_name"`
+}
+
+// GetTeamGetParams defines parameters for GetTeamGet.
+type GetTeamGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
+// PostTeamRemoveMembersJSONBody defines parameters for PostTeamRemoveMembers.
+type PostTeamRemoveMembersJSONBody struct {
+	TeamName string   `json:"team_name"`
+	UserIds  []string `json:"user_ids"`
+}
+
+// GetTeamSettingsGetParams defines parameters for GetTeamSettingsGet.
+type GetTeamSettingsGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
+// PostUsersAvailabilityAddJSONBody defines parameters for PostUsersAvailabilityAdd.
+type PostUsersAvailabilityAddJSONBody struct {
+	EndsAt          time.Time `json:"ends_at"`
+	Reason          *string   `json:"reason,omitempty"`
+	ReassignReviews *bool     `json:"reassign_reviews,omitempty"`
+	StartsAt        time.Time `json:"starts_at"`
+	UserId          string    `json:"user_id"`
+}
+
+// PostUsersAvailabilityDeleteJSONBody defines parameters for PostUsersAvailabilityDelete.
+type PostUsersAvailabilityDeleteJSONBody struct {
+	Id int64 `json:"id"`
+}
+
+// GetUsersAvailabilityListParams defines parameters for GetUsersAvailabilityList.
+type GetUsersAvailabilityListParams struct {
+	// UserId Идентификатор пользователя
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+}
+
+// GetUsersGetReviewParams defines parameters for GetUsersGetReview.
+type GetUsersGetReviewParams struct {
+	// UserId Идентификатор пользователя
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+
+	// Pending Только открытые PR, по которым пользователь ещё не одобрил и не запросил изменения
+	Pending *bool `form:"pending,omitempty" json:"pending,omitempty"`
+}
+
+// PostUsersMoveTeamJSONBody defines parameters for PostUsersMoveTeam.
+type PostUsersMoveTeamJSONBody struct {
+	HandOffReviews *bool `json:"hand_off_reviews,omitempty"`
+
+	// TeamName Новая команда
+	TeamName string `json:"team_name"`
+	UserId   string `json:"user_id"`
+}
+
+// PostUsersSetIsActiveJSONBody defines parameters for PostUsersSetIsActive.
+type PostUsersSetIsActiveJSONBody struct {
+	IsActive bool   `json:"is_active"`
+	UserId   string `json:"user_id"`
+}
+
+// PostUsersSetLevelJSONBody defines parameters for PostUsersSetLevel.
+type PostUsersSetLevelJSONBody struct {
+	// Level Уровень пользователя для политики наставничества (require_senior, junior_shadow)
+	Level  UserLevel `json:"level"`
+	UserId string    `json:"user_id"`
+}
+
+// PostUsersSetMaxOpenReviewsJSONBody defines parameters for PostUsersSetMaxOpenReviews.
+type PostUsersSetMaxOpenReviewsJSONBody struct {
+	// MaxOpenReviews null — использовать лимит команды
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+	UserId         string `json:"user_id"`
+}
+
+// PostUsersSetTagsJSONBody defines parameters for PostUsersSetTags.
+type PostUsersSetTagsJSONBody struct {
+	Tags   []string `json:"tags"`
+	UserId string   `json:"user_id"`
+}
+
+// GetUsersTeamHistoryParams defines parameters for GetUsersTeamHistory.
+type GetUsersTeamHistoryParams struct {
+	// UserId Идентификатор пользователя
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+}
+
+// PostWebhooksGithubJSONBody defines parameters for PostWebhooksGithub.
+type PostWebhooksGithubJSONBody map[string]interface{}
+
+// PostWebhooksGithubParams defines parameters for PostWebhooksGithub.
+type PostWebhooksGithubParams struct {
+	XGitHubEvent *string `json:"X-GitHub-Event,omitempty"`
+
+	// XHubSignature256 sha256=<hex HMAC-SHA256 тела запроса>
+	XHubSignature256 *string `json:"X-Hub-Signature-256,omitempty"`
+}
+
+// PostWebhooksGitlabJSONBody defines parameters for PostWebhooksGitlab.
+type PostWebhooksGitlabJSONBody map[string]interface{}
+
+// PostWebhooksGitlabParams defines parameters for PostWebhooksGitlab.
+type PostWebhooksGitlabParams struct {
+	XGitlabEvent *string `json:"X-Gitlab-Event,omitempty"`
+
+	// XGitlabToken Секретный токен вебхука
+	XGitlabToken *string `json:"X-Gitlab-Token,omitempty"`
+}
+
+// PostWebhooksLoginsDeleteJSONBody defines parameters for PostWebhooksLoginsDelete.
+type PostWebhooksLoginsDeleteJSONBody struct {
+	Login string `json:"login"`
+
+	// Provider Git-хостинг, присылающий вебхуки
+	Provider GitProvider `json:"provider"`
+}
+
+// GetWebhooksLoginsListParams defines parameters for GetWebhooksLoginsList.
+type GetWebhooksLoginsListParams struct {
+	Provider *GitProvider `form:"provider,omitempty" json:"provider,omitempty"`
+}
+
+// PostWebhooksSubscriptionsCreateJSONBody defines parameters for PostWebhooksSubscriptionsCreate.
+type PostWebhooksSubscriptionsCreateJSONBody struct {
+	Events *[]WebhookEventType `json:"events,omitempty"`
+
+	// Secret Ключ подписи запросов; в ответах не возвращается
+	Secret string `json:"secret"`
+	Url    string `json:"url"`
+}
+
+// PostWebhooksSubscriptionsDeleteJSONBody defines parameters for PostWebhooksSubscriptionsDelete.
+type PostWebhooksSubscriptionsDeleteJSONBody struct {
+	SubscriptionId int64 `json:"subscription_id"`
+}
+
+// GetWebhooksSubscriptionsDeliveriesParams defines parameters for GetWebhooksSubscriptionsDeliveries.
+type GetWebhooksSubscriptionsDeliveriesParams struct {
+	SubscriptionId int64                  `form:"subscription_id" json:"subscription_id"`
+	Status         *WebhookDeliveryStatus `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// PostWebhooksSubscriptionsRedeliverJSONBody defines parameters for PostWebhooksSubscriptionsRedeliver.
+type PostWebhooksSubscriptionsRedeliverJSONBody struct {
+	DeliveryId int64 `json:"delivery_id"`
+}
+
+// PostPullRequestCloseJSONRequestBody defines body for PostPullRequestClose for application/json ContentType.
+type PostPullRequestCloseJSONRequestBody PostPullRequestCloseJSONBody
+
+// PostPullRequestCreateJSONRequestBody defines body for PostPullRequestCreate for application/json ContentType.
+type PostPullRequestCreateJSONRequestBody PostPullRequestCreateJSONBody
+
+// PostPullRequestMarkReadyJSONRequestBody defines body for PostPullRequestMarkReady for application/json ContentType.
+type PostPullRequestMarkReadyJSONRequestBody PostPullRequestMarkReadyJSONBody
+
+// PostPullRequestMergeJSONRequestBody defines body for PostPullRequestMerge for application/json ContentType.
+type PostPullRequestMergeJSONRequestBody PostPullRequestMergeJSONBody
+
+// PostPullRequestReassignJSONRequestBody defines body for PostPullRequestReassign for application/json ContentType.
+type PostPullRequestReassignJSONRequestBody PostPullRequestReassignJSONBody
+
+// PostPullRequestReopenJSONRequestBody defines body for PostPullRequestReopen for application/json ContentType.
+type PostPullRequestReopenJSONRequestBody PostPullRequestReopenJSONBody
+
+// PostPullRequestReviewJSONRequestBody defines body for PostPullRequestReview for application/json ContentType.
+type PostPullRequestReviewJSONRequestBody PostPullRequestReviewJSONBody
+
+// PostTeamAddJSONRequestBody defines body for PostTeamAdd for application/json ContentType.
+type PostTeamAddJSONRequestBody = Team
+
+// PostTeamAddMembersJSONRequestBody defines body for PostTeamAddMembers for application/json ContentType.
+type PostTeamAddMembersJSONRequestBody = Team
+
+// PostTeamCodeownersSetJSONRequestBody defines body for PostTeamCodeownersSet for application/json ContentType.
+type PostTeamCodeownersSetJSONRequestBody PostTeamCodeownersSetJSONBody
+
+// PostTeamDeactivateUsersJSONRequestBody defines body for PostTeamDeactivateUsers for application/json ContentType.
+type PostTeamDeactivateUsersJSONRequestBody PostTeamDeactivateUsersJSONBody
+
+// PostTeamDeleteJSONRequestBody defines body for PostTeamDelete for application/json ContentType.
+type PostTeamDeleteJSONRequestBody PostTeamDeleteJSONBody
+
+// PostTeamRemoveMembersJSONRequestBody defines body for PostTeamRemoveMembers for application/json ContentType.
+type PostTeamRemoveMembersJSONRequestBody PostTeamRemoveMembersJSONBody
+
+// PostTeamSettingsSetJSONRequestBody defines body for PostTeamSettingsSet for application/json ContentType.
+type PostTeamSettingsSetJSONRequestBody = TeamSettings
+
+// PostUsersAvailabilityAddJSONRequestBody defines body for PostUsersAvailabilityAdd for application/json ContentType.
+type PostUsersAvailabilityAddJSONRequestBody PostUsersAvailabilityAddJSONBody
+
+// PostUsersAvailabilityDeleteJSONRequestBody defines body for PostUsersAvailabilityDelete for application/json ContentType.
+type PostUsersAvailabilityDeleteJSONRequestBody PostUsersAvailabilityDeleteJSONBody
+
+// PostUsersMoveTeamJSONRequestBody defines body for PostUsersMoveTeam for application/json ContentType.
+type PostUsersMoveTeamJSONRequestBody PostUsersMoveTeamJSONBody
+
+// PostUsersSetIsActiveJSONRequestBody defines body for PostUsersSetIsActive for application/json ContentType.
+type PostUsersSetIsActiveJSONRequestBody PostUsersSetIsActiveJSONBody
+
+// PostUsersSetLevelJSONRequestBody defines body for PostUsersSetLevel for application/json ContentType.
+type PostUsersSetLevelJSONRequestBody PostUsersSetLevelJSONBody
+
+// PostUsersSetMaxOpenReviewsJSONRequestBody defines body for PostUsersSetMaxOpenReviews for application/json ContentType.
+type PostUsersSetMaxOpenReviewsJSONRequestBody PostUsersSetMaxOpenReviewsJSONBody
+
+// PostUsersSetTagsJSONRequestBody defines body for PostUsersSetTags for application/json ContentType.
+type PostUsersSetTagsJSONRequestBody PostUsersSetTagsJSONBody
+
+// PostWebhooksGithubJSONRequestBody defines body for PostWebhooksGithub for application/json ContentType.
+type PostWebhooksGithubJSONRequestBody PostWebhooksGithubJSONBody
+
+// PostWebhooksGitlabJSONRequestBody defines body for PostWebhooksGitlab for application/json ContentType.
+type PostWebhooksGitlabJSONRequestBody PostWebhooksGitlabJSONBody
+
+// PostWebhooksLoginsDeleteJSONRequestBody defines body for PostWebhooksLoginsDelete for application/json ContentType.
+type PostWebhooksLoginsDeleteJSONRequestBody PostWebhooksLoginsDeleteJSONBody
+
+// PostWebhooksLoginsSetJSONRequestBody defines body for PostWebhooksLoginsSet for application/json ContentType.
+type PostWebhooksLoginsSetJSONRequestBody = ExternalLogin
+
+// PostWebhooksSubscriptionsCreateJSONRequestBody defines body for PostWebhooksSubscriptionsCreate for application/json ContentType.
+type PostWebhooksSubscriptionsCreateJSONRequestBody PostWebhooksSubscriptionsCreateJSONBody
+
+// PostWebhooksSubscriptionsDeleteJSONRequestBody defines body for PostWebhooksSubscriptionsDelete for application/json ContentType.
+type PostWebhooksSubscriptionsDeleteJSONRequestBody PostWebhooksSubscriptionsDeleteJSONBody
+
+// PostWebhooksSubscriptionsRedeliverJSONRequestBody defines body for PostWebhooksSubscriptionsRedeliver for application/json ContentType.
+type PostWebhooksSubscriptionsRedeliverJSONRequestBody PostWebhooksSubscriptionsRedeliverJSONBody
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Закрыть PR без merge (идемпотентная операция)
+	// (POST /pullRequest/close)
+	PostPullRequestClose(w http.ResponseWriter, r *http.Request)
+	// Создать PR и автоматически назначить ревьюверов из команды автора
+	// (POST /pullRequest/create)
+	PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
+	// Перевести черновик в готовый к ревью PR и назначить ревьюверов
+	// (POST /pullRequest/markReady)
+	PostPullRequestMarkReady(w http.ResponseWriter, r *http.Request)
+	// Пометить PR как MERGED (идемпотентная операция)
+	// (POST /pullRequest/merge)
+	PostPullRequestMerge(w http.ResponseWriter, r *http.Request)
+	// Переназначить конкретного ревьювера на другого из его команды
+	// (POST /pullRequest/reassign)
+	PostPullRequestReassign(w http.ResponseWriter, r *http.Request)
+	// Переоткрыть закрытый PR
+	// (POST /pullRequest/reopen)
+	PostPullRequestReopen(w http.ResponseWriter, r *http.Request)
+	// Зафиксировать решение ревьювера по PR
+	// (POST /pullRequest/review)
+	PostPullRequestReview(w http.ResponseWriter, r *http.Request)
+	// Создать команду с участниками (создаёт/обновляет пользователей)
+	// (POST /team/add)
+	PostTeamAdd(w http.ResponseWriter, r *http.Request, params PostTeamAddParams)
+	// Добавить участников в существующую команду
+	// (POST /team/addMembers)
+	PostTeamAddMembers(w http.ResponseWriter, r *http.Request)
+	// Получить файл владельцев кода команды
+	// (GET /team/codeowners/get)
+	GetTeamCodeownersGet(w http.ResponseWriter, r *http.Request, params GetTeamCodeownersGetParams)
+	// Загрузить файл владельцев кода команды (синтаксис CODEOWNERS)
+	// (POST /team/codeowners/set)
+	PostTeamCodeownersSet(w http.ResponseWriter, r *http.Request)
+	// Массово деактивировать участников команды
+	// (POST /team/deactivateUsers)
+	PostTeamDeactivateUsers(w http.ResponseWriter, r *http.Request)
+	// Удалить команду
+	// (POST /team/delete)
+	PostTeamDelete(w http.ResponseWriter, r *http.Request)
+	// Получить команду с участниками
+	// (GET /team/get)
+	GetTeamGet(w http.ResponseWriter, r *http.Request, params GetTeamGetParams)
+	// Исключить участников из команды
+	// (POST /team/removeMembers)
+	PostTeamRemoveMembers(w http.ResponseWriter, r *http.Request)
+	// Получить настройки назначения ревьюверов команды
+	// (GET /team/settings/get)
+	GetTeamSettingsGet(w http.ResponseWriter, r *http.Request, params GetTeamSettingsGetParams)
+	// Изменить настройки назначения ревьюверов команды
+	// (POST /team/settings/set)
+	PostTeamSettingsSet(w http.ResponseWriter, r *http.Request)
+	// Добавить период недоступности пользователя (отпуск, болезнь)
+	// (POST /users/availability/add)
+	PostUsersAvailabilityAdd(w http.ResponseWriter, r *http.Request)
+	// Удалить период недоступности
+	// (POST /users/availability/delete)
+	PostUsersAvailabilityDelete(w http.ResponseWriter, r *http.Request)
+	// Периоды недоступности пользователя
+	// (GET /users/availability/list)
+	GetUsersAvailabilityList(w http.ResponseWriter, r *http.Request, params GetUsersAvailabilityListParams)
+	// Получить PR'ы, где пользователь назначен ревьювером
+	// (GET /users/getReview)
+	GetUsersGetReview(w http.ResponseWriter, r *http.Request, params GetUsersGetReviewParams)
+	// Перевести пользователя в другую команду
+	// (POST /users/moveTeam)
+	PostUsersMoveTeam(w http.ResponseWriter, r *http.Request)
+	// Установить флаг активности пользователя
+	// (POST /users/setIsActive)
+	PostUsersSetIsActive(w http.ResponseWriter, r *http.Request)
+	// Установить уровень пользователя
+	// (POST /users/setLevel)
+	PostUsersSetLevel(w http.ResponseWriter, r *http.Request)
+	// Установить лимит открытых ревью пользователя
+	// (POST /users/setMaxOpenReviews)
+	PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request)
+	// Заменить навыки пользователя
+	// (POST /users/setTags)
+	PostUsersSetTags(w http.ResponseWriter, r *http.Request)
+	// История переводов пользователя между командами
+	// (GET /users/teamHistory)
+	GetUsersTeamHistory(w http.ResponseWriter, r *http.Request, params GetUsersTeamHistoryParams)
+	// Принять вебхук GitHub
+	// (POST /webhooks/github)
+	PostWebhooksGithub(w http.ResponseWriter, r *http.Request, params PostWebhooksGithubParams)
+	// Принять вебхук GitLab
+	// (POST /webhooks/gitlab)
+	PostWebhooksGitlab(w http.ResponseWriter, r *http.Request, params PostWebhooksGitlabParams)
+	// Удалить сопоставление логина
+	// (POST /webhooks/logins/delete)
+	PostWebhooksLoginsDelete(w http.ResponseWriter, r *http.Request)
+	// Список сопоставлений логинов
+	// (GET /webhooks/logins/list)
+	GetWebhooksLoginsList(w http.ResponseWriter, r *http.Request, params GetWebhooksLoginsListParams)
+	// Сопоставить логин Git-хостинга пользователю
+	// (POST /webhooks/logins/set)
+	PostWebhooksLoginsSet(w http.ResponseWriter, r *http.Request)
+	// Подписать внешний сервис на события PR
+	// (POST /webhooks/subscriptions/create)
+	PostWebhooksSubscriptionsCreate(w http.ResponseWriter, r *http.Request)
+	// Удалить подписку вместе с её доставками
+	// (POST /webhooks/subscriptions/delete)
+	PostWebhooksSubscriptionsDelete(w http.ResponseWriter, r *http.Request)
+	// Последние доставки подписки
+	// (GET /webhooks/subscriptions/deliveries)
+	GetWebhooksSubscriptionsDeliveries(w http.ResponseWriter, r *http.Request, params GetWebhooksSubscriptionsDeliveriesParams)
+	// Список подписок
+	// (GET /webhooks/subscriptions/list)
+	GetWebhooksSubscriptionsList(w http.ResponseWriter, r *http.Request)
+	// Повторить доставку
+	// (POST /webhooks/subscriptions/redeliver)
+	PostWebhooksSubscriptionsRedeliver(w http.ResponseWriter, r *http.Request)
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// Закрыть PR без merge (идемпотентная операция)
+// (POST /pullRequest/close)
+func (_ Unimplemented) PostPullRequestClose(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Создать PR и автоматически назначить ревьюверов из команды автора
+// (POST /pullRequest/create)
+func (_ Unimplemented) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Перевести черновик в готовый к ревью PR и назначить ревьюверов
+// (POST /pullRequest/markReady)
+func (_ Unimplemented) PostPullRequestMarkReady(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Пометить PR как MERGED (идемпотентная операция)
+// (POST /pullRequest/merge)
+func (_ Unimplemented) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Переназначить конкретного ревьювера на другого из его команды
+// (POST /pullRequest/reassign)
+func (_ Unimplemented) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Переоткрыть закрытый PR
+// (POST /pullRequest/reopen)
+func (_ Unimplemented) PostPullRequestReopen(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Зафиксировать решение ревьювера по PR
+// (POST /pullRequest/review)
+func (_ Unimplemented) PostPullRequestReview(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Создать команду с участниками (создаёт/обновляет пользователей)
+// (POST /team/add)
+func (_ Unimplemented) PostTeamAdd(w http.ResponseWriter, r *http.Request, params PostTeamAddParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Добавить участников в существующую команду
+// (POST /team/addMembers)
+func (_ Unimplemented) PostTeamAddMembers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить файл владельцев кода команды
+// (GET /team/codeowners/get)
+func (_ Unimplemented) GetTeamCodeownersGet(w http.ResponseWriter, r *http.Request, params GetTeamCodeownersGetParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Загрузить файл владельцев кода команды (синтаксис CODEOWNERS)
+// (POST /team/codeowners/set)
+func (_ Unimplemented) PostTeamCodeownersSet(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Массово деактивировать участников команды
+// (POST /team/deactivateUsers)
+func (_ Unimplemented) PostTeamDeactivateUsers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить команду
+// (POST /team/delete)
+func (_ Unimplemented) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить команду с участниками
+// (GET /team/get)
+func (_ Unimplemented) GetTeamGet(w http.ResponseWriter, r *http.Request, params GetTeamGetParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Исключить участников из команды
+// (POST /team/removeMembers)
+func (_ Unimplemented) PostTeamRemoveMembers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить настройки назначения ревьюверов команды
+// (GET /team/settings/get)
+func (_ Unimplemented) GetTeamSettingsGet(w http.ResponseWriter, r *http.Request, params GetTeamSettingsGetParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Изменить настройки назначения ревьюверов команды
+// (POST /team/settings/set)
+func (_ Unimplemented) PostTeamSettingsSet(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Добавить период недоступности пользователя (отпуск, болезнь)
+// (POST /users/availability/add)
+func (_ Unimplemented) PostUsersAvailabilityAdd(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить период недоступности
+// (POST /users/availability/delete)
+func (_ Unimplemented) PostUsersAvailabilityDelete(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Периоды недоступности пользователя
+// (GET /users/availability/list)
+func (_ Unimplemented) GetUsersAvailabilityList(w http.ResponseWriter, r *http.Request, params GetUsersAvailabilityListParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить PR'ы, где пользователь назначен ревьювером
+// (GET /users/getReview)
+func (_ Unimplemented) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params GetUsersGetReviewParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Перевести пользователя в другую команду
+// (POST /users/moveTeam)
+func (_ Unimplemented) PostUsersMoveTeam(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Установить флаг активности пользователя
+// (POST /users/setIsActive)
+func (_ Unimplemented) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Установить уровень пользователя
+// (POST /users/setLevel)
+func (_ Unimplemented) PostUsersSetLevel(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Установить лимит открытых ревью пользователя
+// (POST /users/setMaxOpenReviews)
+func (_ Unimplemented) PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Заменить навыки пользователя
+// (POST /users/setTags)
+func (_ Unimplemented) PostUsersSetTags(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// История переводов пользователя между командами
+// (GET /users/teamHistory)
+func (_ Unimplemented) GetUsersTeamHistory(w http.ResponseWriter, r *http.Request, params GetUsersTeamHistoryParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Принять вебхук GitHub
+// (POST /webhooks/github)
+func (_ Unimplemented) PostWebhooksGithub(w http.ResponseWriter, r *http.Request, params PostWebhooksGithubParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Принять вебхук GitLab
+// (POST /webhooks/gitlab)
+func (_ Unimplemented) PostWebhooksGitlab(w http.ResponseWriter, r *http.Request, params PostWebhooksGitlabParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить сопоставление логина
+// (POST /webhooks/logins/delete)
+func (_ Unimplemented) PostWebhooksLoginsDelete(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Список сопоставлений логинов
+// (GET /webhooks/logins/list)
+func (_ Unimplemented) GetWebhooksLoginsList(w http.ResponseWriter, r *http.Request, params GetWebhooksLoginsListParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Сопоставить логин Git-хостинга пользователю
+// (POST /webhooks/logins/set)
+func (_ Unimplemented) PostWebhooksLoginsSet(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Подписать внешний сервис на события PR
+// (POST /webhooks/subscriptions/create)
+func (_ Unimplemented) PostWebhooksSubscriptionsCreate(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить подписку вместе с её доставками
+// (POST /webhooks/subscriptions/delete)
+func (_ Unimplemented) PostWebhooksSubscriptionsDelete(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Последние доставки подписки
+// (GET /webhooks/subscriptions/deliveries)
+func (_ Unimplemented) GetWebhooksSubscriptionsDeliveries(w http.ResponseWriter, r *http.Request, params GetWebhooksSubscriptionsDeliveriesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Список подписок
+// (GET /webhooks/subscriptions/list)
+func (_ Unimplemented) GetWebhooksSubscriptionsList(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Повторить доставку
+// (POST /webhooks/subscriptions/redeliver)
+func (_ Unimplemented) PostWebhooksSubscriptionsRedeliver(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// PostPullRequestClose operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestClose(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestClose(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestCreate(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestMarkReady operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestMarkReady(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestMarkReady(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestMerge operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestMerge(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestReassign operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestReassign(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestReopen operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestReopen(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestReopen(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestReview operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestReview(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestReview(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamAdd operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostTeamAddParams
+
+	// ------------- Optional query parameter "move_members" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "move_members", r.URL.Query(), &params.MoveMembers)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "move_members", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamAdd(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamAddMembers operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamAddMembers(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamAddMembers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeamCodeownersGet operation middleware
+func (siw *ServerInterfaceWrapper) GetTeamCodeownersGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTeamCodeownersGetParams
+
+	// ------------- Required query parameter "team_name" -------------
+
+	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeamCodeownersGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamCodeownersSet operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamCodeownersSet(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamCodeownersSet(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamDeactivateUsers operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamDeactivateUsers(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamDeactivateUsers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamDelete(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeamGet operation middleware
+func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTeamGetParams
+
+	// ------------- Required query parameter "team_name" -------------
+
+	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeamGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamRemoveMembers operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamRemoveMembers(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamRemoveMembers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeamSettingsGet operation middleware
+func (siw *ServerInterfaceWrapper) GetTeamSettingsGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTeamSettingsGetParams
+
+	// ------------- Required query parameter "team_name" -------------
+
+	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeamSettingsGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamSettingsSet operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamSettingsSet(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamSettingsSet(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersAvailabilityAdd operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersAvailabilityAdd(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersAvailabilityAdd(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersAvailabilityDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersAvailabilityDelete(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersAvailabilityDelete(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUsersAvailabilityList operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersAvailabilityList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsersAvailabilityListParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersAvailabilityList(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUsersGetReview operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersGetReview(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsersGetReviewParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pending" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pending", r.URL.Query(), &params.Pending)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pending", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersGetReview(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersMoveTeam operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersMoveTeam(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersMoveTeam(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersSetIsActive operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersSetIsActive(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersSetLevel operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersSetLevel(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersSetLevel(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersSetMaxOpenReviews operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersSetMaxOpenReviews(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersSetTags operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersSetTags(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersSetTags(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUsersTeamHistory operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersTeamHistory(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsersTeamHistoryParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersTeamHistory(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostWebhooksGithub operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksGithub(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostWebhooksGithubParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-GitHub-Event" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-GitHub-Event")]; found {
+		var XGitHubEvent string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-GitHub-Event", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-GitHub-Event", valueList[0], &XGitHubEvent, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-GitHub-Event", Err: err})
+			return
+		}
+
+		params.XGitHubEvent = &XGitHubEvent
+
+	}
+
+	// ------------- Optional header parameter "X-Hub-Signature-256" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Hub-Signature-256")]; found {
+		var XHubSignature256 string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Hub-Signature-256", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Hub-Signature-256", valueList[0], &XHubSignature256, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Hub-Signature-256", Err: err})
+			return
+		}
+
+		params.XHubSignature256 = &XHubSignature256
 
-// User defines model for User.
-type User struct {
-	IsActive bool   `json:"is_active"`
-	TeamName string `json:"team_name"`
-	UserId   string `json:"user_id"`
-	Username string `json:"username"`
-}
+	}
 
-// TeamNameQuery defines model for TeamNameQuery.
-type TeamNameQuery = string
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostWebhooksGithub(w, r, params)
+	}))
 
-// UserIdQuery defines model for UserIdQuery.
-type UserIdQuery = string
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-// PostPullRequestCreateJSONBody defines parameters for PostPullRequestCreate.
-type PostPullRequestCreateJSONBody struct {
-	AuthorId        string `json:"author_id"`
-	PullRequestId   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
+	handler.ServeHTTP(w, r)
 }
 
-// PostPullRequestMergeJSONBody defines parameters for PostPullRequestMerge.
-type PostPullRequestMergeJSONBody struct {
-	PullRequestId string `json:"pull_request_id"`
-}
+// PostWebhooksGitlab operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksGitlab(w http.ResponseWriter, r *http.Request) {
 
-// PostPullRequestReassignJSONBody defines parameters for PostPullRequestReassign.
-type PostPullRequestReassignJSONBody struct {
-	OldUserId     string `json:"old_user_id"`
-	PullRequestId string `json:"pull_request_id"`
-}
+	var err error
 
-// GetTeamGetParams defines parameters for GetTeamGet.
-type GetTeamGetParams struct {
-	// TeamName Уникальное имя команды
-	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostWebhooksGitlabParams
 
-// GetUsersGetReviewParams defines parameters for GetUsersGetReview.
-type GetUsersGetReviewParams struct {
-	// UserId Идентификатор пользователя
-	UserId UserIdQuery `form:"user_id" json:"user_id"`
-}
+	headers := r.Header
 
-// PostUsersSetIsActiveJSONBody defines parameters for PostUsersSetIsActive.
-type PostUsersSetIsActiveJSONBody struct {
-	IsActive bool   `json:"is_active"`
-	UserId   string `json:"user_id"`
-}
+	// ------------- Optional header parameter "X-Gitlab-Event" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Gitlab-Event")]; found {
+		var XGitlabEvent string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Gitlab-Event", Count: n})
+			return
+		}
 
-// PostPullRequestCreateJSONRequestBody defines body for PostPullRequestCreate for application/json ContentType.
-type PostPullRequestCreateJSONRequestBody PostPullRequestCreateJSONBody
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Gitlab-Event", valueList[0], &XGitlabEvent, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Gitlab-Event", Err: err})
+			return
+		}
 
-// PostPullRequestMergeJSONRequestBody defines body for PostPullRequestMerge for application/json ContentType.
-type PostPullRequestMergeJSONRequestBody PostPullRequestMergeJSONBody
+		params.XGitlabEvent = &XGitlabEvent
 
-// PostPullRequestReassignJSONRequestBody defines body for PostPullRequestReassign for application/json ContentType.
-type PostPullRequestReassignJSONRequestBody PostPullRequestReassignJSONBody
+	}
 
-// PostTeamAddJSONRequestBody defines body for PostTeamAdd for application/json ContentType.
-type PostTeamAddJSONRequestBody = Team
+	// ------------- Optional header parameter "X-Gitlab-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Gitlab-Token")]; found {
+		var XGitlabToken string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Gitlab-Token", Count: n})
+			return
+		}
 
-// PostUsersSetIsActiveJSONRequestBody defines body for PostUsersSetIsActive for application/json ContentType.
-type PostUsersSetIsActiveJSONRequestBody PostUsersSetIsActiveJSONBody
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Gitlab-Token", valueList[0], &XGitlabToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Gitlab-Token", Err: err})
+			return
+		}
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// Создать PR и автоматически назначить до 2 ревьюверов из команды автора
-	// (POST /pullRequest/create)
-	PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
-	// Пометить PR как MERGED (идемпотентная операция)
-	// (POST /pullRequest/merge)
-	PostPullRequestMerge(w http.ResponseWriter, r *http.Request)
-	// Переназначить конкретного ревьювера на другого из его команды
-	// (POST /pullRequest/reassign)
-	PostPullRequestReassign(w http.ResponseWriter, r *http.Request)
-	// Создать команду с участниками (создаёт/обновляет пользователей)
-	// (POST /team/add)
-	PostTeamAdd(w http.ResponseWriter, r *http.Request)
-	// Получить команду с участниками
-	// (GET /team/get)
-	GetTeamGet(w http.ResponseWriter, r *http.Request, params GetTeamGetParams)
-	// Получить PR'ы, где пользователь назначен ревьювером
-	// (GET /users/getReview)
-	GetUsersGetReview(w http.ResponseWriter, r *http.Request, params GetUsersGetReviewParams)
-	// Установить флаг активности пользователя
-	// (POST /users/setIsActive)
-	PostUsersSetIsActive(w http.ResponseWriter, r *http.Request)
-}
+		params.XGitlabToken = &XGitlabToken
 
-// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+	}
 
-type Unimplemented struct{}
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostWebhooksGitlab(w, r, params)
+	}))
 
-// Создать PR и автоматически назначить до 2 ревьюверов из команды автора
-// (POST /pullRequest/create)
-func (_ Unimplemented) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-// Пометить PR как MERGED (идемпотентная операция)
-// (POST /pullRequest/merge)
-func (_ Unimplemented) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
+	handler.ServeHTTP(w, r)
 }
 
-// Переназначить конкретного ревьювера на другого из его команды
-// (POST /pullRequest/reassign)
-func (_ Unimplemented) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+// PostWebhooksLoginsDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksLoginsDelete(w http.ResponseWriter, r *http.Request) {
 
-// Создать команду с участниками (создаёт/обновляет пользователей)
-// (POST /team/add)
-func (_ Unimplemented) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostWebhooksLoginsDelete(w, r)
+	}))
 
-// Получить команду с участниками
-// (GET /team/get)
-func (_ Unimplemented) GetTeamGet(w http.ResponseWriter, r *http.Request, params GetTeamGetParams) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-// Получить PR'ы, где пользователь назначен ревьювером
-// (GET /users/getReview)
-func (_ Unimplemented) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params GetUsersGetReviewParams) {
-	w.WriteHeader(http.StatusNotImplemented)
+	handler.ServeHTTP(w, r)
 }
 
-// Установить флаг активности пользователя
-// (POST /users/setIsActive)
-func (_ Unimplemented) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+// GetWebhooksLoginsList operation middleware
+func (siw *ServerInterfaceWrapper) GetWebhooksLoginsList(w http.ResponseWriter, r *http.Request) {
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
-}
+	var err error
 
-type MiddlewareFunc func(http.Handler) http.Handler
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetWebhooksLoginsListParams
 
-// PostPullRequestCreate operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
+	// ------------- Optional query parameter "provider" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "provider", r.URL.Query(), &params.Provider)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "provider", Err: err})
+		return
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestCreate(w, r)
+		siw.Handler.GetWebhooksLoginsList(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -243,11 +1743,11 @@ func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter,
 	handler.ServeHTTP(w, r)
 }
 
-// PostPullRequestMerge operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
+// PostWebhooksLoginsSet operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksLoginsSet(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestMerge(w, r)
+		siw.Handler.PostWebhooksLoginsSet(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -257,11 +1757,11 @@ func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
-// PostPullRequestReassign operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
+// PostWebhooksSubscriptionsCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksSubscriptionsCreate(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestReassign(w, r)
+		siw.Handler.PostWebhooksSubscriptionsCreate(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -271,11 +1771,11 @@ func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter
 	handler.ServeHTTP(w, r)
 }
 
-// PostTeamAdd operation middleware
-func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+// PostWebhooksSubscriptionsDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksSubscriptionsDelete(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostTeamAdd(w, r)
+		siw.Handler.PostWebhooksSubscriptionsDelete(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -285,31 +1785,39 @@ func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
-// GetTeamGet operation middleware
-func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Request) {
+// GetWebhooksSubscriptionsDeliveries operation middleware
+func (siw *ServerInterfaceWrapper) GetWebhooksSubscriptionsDeliveries(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTeamGetParams
+	var params GetWebhooksSubscriptionsDeliveriesParams
 
-	// ------------- Required query parameter "team_name" -------------
+	// ------------- Required query parameter "subscription_id" -------------
 
-	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("subscription_id"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "subscription_id"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	err = runtime.BindQueryParameter("form", true, true, "subscription_id", r.URL.Query(), &params.SubscriptionId)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "subscription_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTeamGet(w, r, params)
+		siw.Handler.GetWebhooksSubscriptionsDeliveries(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -319,31 +1827,11 @@ func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Req
 	handler.ServeHTTP(w, r)
 }
 
-// GetUsersGetReview operation middleware
-func (siw *ServerInterfaceWrapper) GetUsersGetReview(w http.ResponseWriter, r *http.Request) {
-
-	var err error
-
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetUsersGetReviewParams
-
-	// ------------- Required query parameter "user_id" -------------
-
-	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
-		return
-	}
-
-	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
-		return
-	}
+// GetWebhooksSubscriptionsList operation middleware
+func (siw *ServerInterfaceWrapper) GetWebhooksSubscriptionsList(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetUsersGetReview(w, r, params)
+		siw.Handler.GetWebhooksSubscriptionsList(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -353,11 +1841,11 @@ func (siw *ServerInterfaceWrapper) GetUsersGetReview(w http.ResponseWriter, r *h
 	handler.ServeHTTP(w, r)
 }
 
-// PostUsersSetIsActive operation middleware
-func (siw *ServerInterfaceWrapper) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
+// PostWebhooksSubscriptionsRedeliver operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooksSubscriptionsRedeliver(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostUsersSetIsActive(w, r)
+		siw.Handler.PostWebhooksSubscriptionsRedeliver(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -480,27 +1968,117 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/close", wrapper.PostPullRequestClose)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/create", wrapper.PostPullRequestCreate)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/markReady", wrapper.PostPullRequestMarkReady)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/merge", wrapper.PostPullRequestMerge)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/reassign", wrapper.PostPullRequestReassign)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/reopen", wrapper.PostPullRequestReopen)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/review", wrapper.PostPullRequestReview)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/team/add", wrapper.PostTeamAdd)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/addMembers", wrapper.PostTeamAddMembers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/team/codeowners/get", wrapper.GetTeamCodeownersGet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/codeowners/set", wrapper.PostTeamCodeownersSet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/deactivateUsers", wrapper.PostTeamDeactivateUsers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/delete", wrapper.PostTeamDelete)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/team/get", wrapper.GetTeamGet)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/removeMembers", wrapper.PostTeamRemoveMembers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/team/settings/get", wrapper.GetTeamSettingsGet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/settings/set", wrapper.PostTeamSettingsSet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/availability/add", wrapper.PostUsersAvailabilityAdd)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/availability/delete", wrapper.PostUsersAvailabilityDelete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/availability/list", wrapper.GetUsersAvailabilityList)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/users/getReview", wrapper.GetUsersGetReview)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/moveTeam", wrapper.PostUsersMoveTeam)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/users/setIsActive", wrapper.PostUsersSetIsActive)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setLevel", wrapper.PostUsersSetLevel)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setMaxOpenReviews", wrapper.PostUsersSetMaxOpenReviews)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setTags", wrapper.PostUsersSetTags)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/teamHistory", wrapper.GetUsersTeamHistory)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/github", wrapper.PostWebhooksGithub)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/gitlab", wrapper.PostWebhooksGitlab)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/logins/delete", wrapper.PostWebhooksLoginsDelete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/webhooks/logins/list", wrapper.GetWebhooksLoginsList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/logins/set", wrapper.PostWebhooksLoginsSet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/subscriptions/create", wrapper.PostWebhooksSubscriptionsCreate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/subscriptions/delete", wrapper.PostWebhooksSubscriptionsDelete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/webhooks/subscriptions/deliveries", wrapper.GetWebhooksSubscriptionsDeliveries)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/webhooks/subscriptions/list", wrapper.GetWebhooksSubscriptionsList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks/subscriptions/redeliver", wrapper.PostWebhooksSubscriptionsRedeliver)
+	})
 
 	return r
 }
@@ -0,0 +1,173 @@
+// Package codeowners parses ownership files in CODEOWNERS syntax and
+// resolves the owners of changed paths.
+//
+// Each non-empty line that is not a comment holds a pattern followed by
+// owners. Owners are written as @user_id for a user and @org/team_name for
+// a team. Patterns follow the usual CODEOWNERS rules: a leading or inner
+// slash anchors the pattern to the repository root, a trailing slash
+// matches a directory's contents, * and ? stay within one path segment and
+// ** spans segments. When several rules match a path, the last one wins; a
+// rule without owners leaves its paths unowned.
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Owner is either a user or a team; exactly one of the fields is set.
+type Owner struct {
+	User string
+	Team string
+}
+
+func (o Owner) String() string {
+	if o.Team != "" {
+		return "@team/" + o.Team
+	}
+	return "@" + o.User
+}
+
+type Rule struct {
+	Pattern string
+	Owners  []Owner
+
+	re *regexp.Regexp
+}
+
+// Match reports whether the rule's pattern matches path, given relative to
+// the repository root.
+func (r Rule) Match(path string) bool {
+	return r.re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+type File struct {
+	Rules []Rule
+}
+
+// Parse reads an ownership file. Errors name the offending line.
+func Parse(content string) (*File, error) {
+	f := &File{Rules: []Rule{}}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule, err := parseRule(fields[0], fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+	return f, nil
+}
+
+// Owners returns the owners of the given paths, in order of first
+// appearance and without duplicates.
+func (f *File) Owners(paths []string) []Owner {
+	var owners []Owner
+	seen := map[Owner]struct{}{}
+	for _, path := range paths {
+		for i := len(f.Rules) - 1; i >= 0; i-- {
+			if !f.Rules[i].Match(path) {
+				continue
+			}
+			for _, o := range f.Rules[i].Owners {
+				if _, dup := seen[o]; dup {
+					continue
+				}
+				seen[o] = struct{}{}
+				owners = append(owners, o)
+			}
+			break
+		}
+	}
+	return owners
+}
+
+func parseRule(pattern string, tokens []string) (Rule, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return Rule{}, fmt.Errorf("negated pattern %q is not supported", pattern)
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+
+	rule := Rule{Pattern: pattern, Owners: []Owner{}, re: re}
+	for _, tok := range tokens {
+		if strings.HasPrefix(tok, "#") {
+			break
+		}
+		owner, err := parseOwner(tok)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Owners = append(rule.Owners, owner)
+	}
+	return rule, nil
+}
+
+func parseOwner(tok string) (Owner, error) {
+	name, ok := strings.CutPrefix(tok, "@")
+	if !ok || name == "" || strings.Contains(name, "@") {
+		return Owner{}, fmt.Errorf("owner %q must be @user_id or @org/team_name", tok)
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		team := name[i+1:]
+		if team == "" || i == 0 {
+			return Owner{}, fmt.Errorf("owner %q must be @user_id or @org/team_name", tok)
+		}
+		return Owner{Team: team}, nil
+	}
+	return Owner{User: name}, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	// A pattern naming a file or directory also matches everything below
+	// it, but a wildcard in the last segment stays within that segment.
+	last := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.ContainsAny(last, "*?"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
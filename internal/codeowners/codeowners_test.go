@@ -0,0 +1,80 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRule_Match(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "main.go", true},
+		{"*", "cmd/server/main.go", true},
+		{"*.go", "internal/repo/repo.go", true},
+		{"*.go", "README.md", false},
+		{"/docs/", "docs/index.md", true},
+		{"/docs/", "api/docs/index.md", false},
+		{"docs/", "api/docs/index.md", true},
+		{"docs/", "docs", false},
+		{"docs", "api/docs/index.md", true},
+		{"internal/*.go", "internal/main.go", true},
+		{"internal/*.go", "internal/repo/repo.go", false},
+		{"docs/*", "docs/b.md", true},
+		{"docs/*", "docs/sub/x", false},
+		{"internal/**/*.sql", "internal/migrations/init.sql", true},
+		{"internal/**/*.sql", "internal/init.sql", true},
+		{"internal/**", "internal/repo/repo.go", true},
+		{"/openapi.yaml", "/openapi.yaml", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file/.txt", false},
+	}
+	for _, tc := range cases {
+		f, err := Parse(tc.pattern + " @owner")
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.pattern, err)
+		}
+		if got := f.Rules[0].Match(tc.path); got != tc.want {
+			t.Errorf("%q matching %q: expected %v, got %v", tc.pattern, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestFile_OwnersLastRuleWins(t *testing.T) {
+	f, err := Parse(`
+# default owners
+*                 @u1 @acme/backend
+/internal/repo/   @u2   # storage
+*.md
+/docs/            @u3 @u1
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := f.Owners([]string{"internal/repo/repo.go", "README.md", "docs/api.md", "cmd/main.go"})
+	want := []Owner{{User: "u2"}, {User: "u3"}, {User: "u1"}, {Team: "backend"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := f.Owners([]string{"README.md"}); len(got) != 0 {
+		t.Fatalf("expected README.md to be unowned, got %v", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, content := range []string{
+		"*.go u1",
+		"*.go @",
+		"*.go user@example.com",
+		"*.go @acme/",
+		"!*.go @u1",
+		"/ @u1",
+	} {
+		if _, err := Parse("# header\n" + content); err == nil {
+			t.Errorf("expected %q to fail", content)
+		}
+	}
+}
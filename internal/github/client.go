@@ -0,0 +1,134 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.github.com"
+
+// Client requests and removes pull request reviewers through the GitHub
+// REST API.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client authenticating with token against baseURL,
+// or DefaultBaseURL when it is empty.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// APIError is a non-successful response of the GitHub API.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// RateLimited is set when the request was refused because the token
+	// ran out of requests.
+	RateLimited bool
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github: %d %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether repeating the request later may succeed.
+func (e *APIError) Temporary() bool {
+	return e.RateLimited || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// RequestReviewers asks logins to review the pull request prID, given as
+// owner/repo#number.
+func (c *Client) RequestReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.reviewers(ctx, http.MethodPost, prID, logins)
+}
+
+// RemoveReviewers withdraws the review requests of logins on prID.
+func (c *Client) RemoveReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.reviewers(ctx, http.MethodDelete, prID, logins)
+}
+
+func (c *Client) reviewers(ctx context.Context, method, prID string, logins []string) error {
+	repo, number, err := ParsePullRequestID(prID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return fmt.Errorf("encode reviewers: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", c.baseURL, repo, number)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	return &APIError{
+		StatusCode:  resp.StatusCode,
+		Message:     body.Message,
+		RateLimited: resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0",
+	}
+}
+
+// InvalidIDError reports a pull request id that doesn't name a GitHub pull
+// request.
+type InvalidIDError struct {
+	ID string
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("github: %q is not an owner/repo#number pull request id", e.ID)
+}
+
+func (e *InvalidIDError) Temporary() bool {
+	return false
+}
+
+// ParsePullRequestID splits an owner/repo#number id as built by
+// PullRequestEvent.PullRequestID.
+func ParsePullRequestID(prID string) (repo string, number int, err error) {
+	repo, num, ok := strings.Cut(prID, "#")
+	if ok {
+		number, err = strconv.Atoi(num)
+	}
+	if !ok || err != nil || number <= 0 || strings.Count(repo, "/") != 1 {
+		return "", 0, &InvalidIDError{ID: prID}
+	}
+	return repo, number, nil
+}
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Reviewers(t *testing.T) {
+	type call struct {
+		method, path string
+		reviewers    []string
+	}
+	var calls []call
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer t0ken" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/vnd.github+json" {
+			t.Errorf("unexpected Accept %q", got)
+		}
+		var body struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		calls = append(calls, call{r.Method, r.URL.Path, body.Reviewers})
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(`{"number": 42}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/", "t0ken")
+	ctx := context.Background()
+	if err := c.RequestReviewers(ctx, "acme/api#42", []string{"octocat", "hubot"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.RemoveReviewers(ctx, "acme/api#42", []string{"hubot"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []call{
+		{http.MethodPost, "/repos/acme/api/pulls/42/requested_reviewers", []string{"octocat", "hubot"}},
+		{http.MethodDelete, "/repos/acme/api/pulls/42/requested_reviewers", []string{"hubot"}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("expected %+v, got %+v", want, calls)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	cases := map[string]struct {
+		status    int
+		remaining string
+		temporary bool
+	}{
+		"not a collaborator": {http.StatusUnprocessableEntity, "", false},
+		"not found":          {http.StatusNotFound, "", false},
+		"forbidden":          {http.StatusForbidden, "4999", false},
+		"rate limited":       {http.StatusForbidden, "0", true},
+		"too many requests":  {http.StatusTooManyRequests, "", true},
+		"unavailable":        {http.StatusBadGateway, "", true},
+	}
+	for name, tc := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tc.remaining != "" {
+				w.Header().Set("X-RateLimit-Remaining", tc.remaining)
+			}
+			w.WriteHeader(tc.status)
+			_, _ = w.Write([]byte(`{"message": "nope"}`))
+		}))
+
+		err := NewClient(srv.URL, "t0ken").RequestReviewers(context.Background(), "acme/api#42", []string{"octocat"})
+		srv.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%s: expected an APIError, got %v", name, err)
+		}
+		if apiErr.StatusCode != tc.status || apiErr.Message != "nope" || apiErr.Temporary() != tc.temporary {
+			t.Errorf("%s: unexpected error %+v (temporary %v)", name, apiErr, apiErr.Temporary())
+		}
+	}
+
+	err := NewClient("http://127.0.0.1:0", "t0ken").RequestReviewers(context.Background(), "platform/api!7", []string{"octocat"})
+	var idErr *InvalidIDError
+	if !errors.As(err, &idErr) || idErr.Temporary() {
+		t.Fatalf("expected a permanent InvalidIDError, got %v", err)
+	}
+}
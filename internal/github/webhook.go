@@ -0,0 +1,92 @@
+// Package github reads pull request webhooks sent by GitHub.
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing X-Hub-Signature-256 header")
+	ErrInvalidSignature = errors.New("signature does not match the payload")
+)
+
+// VerifySignature checks header, the value of X-Hub-Signature-256, against
+// the HMAC-SHA256 of body keyed with secret.
+func VerifySignature(secret, body []byte, header string) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+	hexSig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(hexSig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the X-Hub-Signature-256 value GitHub sends for body. The
+// service signs its own outgoing webhooks the same way.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PullRequestEvent is the part of a pull_request event payload the service
+// acts on.
+type PullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func ParsePullRequestEvent(body []byte) (PullRequestEvent, error) {
+	var ev PullRequestEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return PullRequestEvent{}, fmt.Errorf("decode pull_request event: %w", err)
+	}
+	if ev.Action == "" || ev.Number <= 0 || ev.Repository.FullName == "" {
+		return PullRequestEvent{}, errors.New("pull_request event must have action, number and repository.full_name")
+	}
+	return ev, nil
+}
+
+// PullRequestID identifies the pull request as owner/repo#number.
+func (ev PullRequestEvent) PullRequestID() string {
+	return fmt.Sprintf("%s#%d", ev.Repository.FullName, ev.Number)
+}
+
+func (ev PullRequestEvent) Labels() []string {
+	labels := make([]string, 0, len(ev.PullRequest.Labels))
+	for _, l := range ev.PullRequest.Labels {
+		labels = append(labels, l.Name)
+	}
+	return labels
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("It's a Secret to Everybody")
+	body := []byte("Hello, World!")
+
+	// Example from GitHub's webhook documentation.
+	const documented = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got := Sign(secret, body); got != documented {
+		t.Fatalf("expected %s, got %s", documented, got)
+	}
+	if err := VerifySignature(secret, body, documented); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		body   []byte
+		header string
+		want   error
+	}{
+		"missing":     {body, "", ErrMissingSignature},
+		"sha1":        {body, "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59", ErrInvalidSignature},
+		"not hex":     {body, "sha256=zz", ErrInvalidSignature},
+		"tampered":    {[]byte("Hello, World?"), documented, ErrInvalidSignature},
+		"other token": {body, Sign([]byte("other"), body), ErrInvalidSignature},
+	}
+	for name, tc := range cases {
+		if err := VerifySignature(secret, tc.body, tc.header); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestParsePullRequestEvent(t *testing.T) {
+	ev, err := ParsePullRequestEvent(readFixture(t, "pull_request_closed_merged.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.Action != "closed" || !ev.PullRequest.Merged || ev.PullRequest.User.Login != "octocat" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if id := ev.PullRequestID(); id != "acme/api#42" {
+		t.Fatalf("expected acme/api#42, got %s", id)
+	}
+	if labels := ev.Labels(); !reflect.DeepEqual(labels, []string{"backend"}) {
+		t.Fatalf("expected [backend], got %v", labels)
+	}
+
+	draft, err := ParsePullRequestEvent(readFixture(t, "pull_request_opened_draft.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !draft.PullRequest.Draft || draft.PullRequestID() != "acme/api#43" {
+		t.Fatalf("unexpected event: %+v", draft)
+	}
+
+	for _, body := range []string{`{`, `{"action":"opened"}`, `{"action":"opened","number":1}`} {
+		if _, err := ParsePullRequestEvent([]byte(body)); err == nil {
+			t.Errorf("expected %s to fail", body)
+		}
+	}
+}
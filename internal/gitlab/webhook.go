@@ -0,0 +1,101 @@
+// Package gitlab reads merge request webhooks sent by GitLab.
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMissingToken = errors.New("missing X-Gitlab-Token header")
+	ErrInvalidToken = errors.New("token does not match")
+)
+
+// VerifyToken checks header, the value of X-Gitlab-Token, against the
+// secret token configured for the hook.
+func VerifyToken(secret, header string) error {
+	if header == "" {
+		return ErrMissingToken
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(header)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+type draftChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+// MergeRequestEvent is the part of a merge_request hook payload the
+// service acts on. User is whoever triggered the event, which for an
+// opened merge request is its author.
+type MergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID    int    `json:"iid"`
+		Title  string `json:"title"`
+		Action string `json:"action"`
+		Draft  bool   `json:"draft"`
+		// WorkInProgress is what GitLab before 13.x called Draft.
+		WorkInProgress bool `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+	Changes struct {
+		Draft          *draftChange `json:"draft"`
+		WorkInProgress *draftChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+func ParseMergeRequestEvent(body []byte) (MergeRequestEvent, error) {
+	var ev MergeRequestEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return MergeRequestEvent{}, fmt.Errorf("decode merge_request event: %w", err)
+	}
+	if ev.ObjectKind != "merge_request" {
+		return MergeRequestEvent{}, fmt.Errorf("object_kind %q is not merge_request", ev.ObjectKind)
+	}
+	if ev.ObjectAttributes.Action == "" || ev.ObjectAttributes.IID <= 0 || ev.Project.PathWithNamespace == "" {
+		return MergeRequestEvent{}, errors.New("merge_request event must have object_attributes.action, object_attributes.iid and project.path_with_namespace")
+	}
+	return ev, nil
+}
+
+// PullRequestID identifies the merge request as group/project!iid.
+func (ev MergeRequestEvent) PullRequestID() string {
+	return fmt.Sprintf("%s!%d", ev.Project.PathWithNamespace, ev.ObjectAttributes.IID)
+}
+
+func (ev MergeRequestEvent) IsDraft() bool {
+	return ev.ObjectAttributes.Draft || ev.ObjectAttributes.WorkInProgress
+}
+
+// DraftChange reports whether the event toggles the draft state and, if
+// so, whether the merge request is a draft now.
+func (ev MergeRequestEvent) DraftChange() (changed, draft bool) {
+	for _, c := range []*draftChange{ev.Changes.Draft, ev.Changes.WorkInProgress} {
+		if c != nil && c.Previous != c.Current {
+			return true, c.Current
+		}
+	}
+	return false, false
+}
+
+func (ev MergeRequestEvent) LabelTitles() []string {
+	labels := make([]string, 0, len(ev.Labels))
+	for _, l := range ev.Labels {
+		labels = append(labels, l.Title)
+	}
+	return labels
+}
@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func TestVerifyToken(t *testing.T) {
+	if err := VerifyToken("s3cret", "s3cret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyToken("s3cret", ""); !errors.Is(err, ErrMissingToken) {
+		t.Fatalf("expected %v, got %v", ErrMissingToken, err)
+	}
+	for _, token := range []string{"s3cre", "s3cret ", "S3CRET"} {
+		if err := VerifyToken("s3cret", token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%q: expected %v, got %v", token, ErrInvalidToken, err)
+		}
+	}
+}
+
+func TestParseMergeRequestEvent(t *testing.T) {
+	ev, err := ParseMergeRequestEvent(readFixture(t, "merge_request_open.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.ObjectAttributes.Action != "open" || ev.User.Username != "jdoe" || ev.IsDraft() {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if id := ev.PullRequestID(); id != "platform/api!7" {
+		t.Fatalf("expected platform/api!7, got %s", id)
+	}
+	if labels := ev.LabelTitles(); !reflect.DeepEqual(labels, []string{"backend"}) {
+		t.Fatalf("expected [backend], got %v", labels)
+	}
+
+	cases := map[string]struct {
+		changed, draft bool
+	}{
+		"merge_request_update_ready.json": {true, false},
+		"merge_request_update_draft.json": {true, true},
+		"merge_request_update_title.json": {false, false},
+	}
+	for name, want := range cases {
+		ev, err := ParseMergeRequestEvent(readFixture(t, name))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if changed, draft := ev.DraftChange(); changed != want.changed || draft != want.draft {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", name, want.changed, want.draft, changed, draft)
+		}
+	}
+
+	for _, body := range []string{
+		`{`,
+		`{"object_kind":"push"}`,
+		`{"object_kind":"merge_request","object_attributes":{"action":"open","iid":1}}`,
+	} {
+		if _, err := ParseMergeRequestEvent([]byte(body)); err == nil {
+			t.Errorf("expected %s to fail", body)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
@@ -10,10 +11,7 @@ import (
 	"pr-reviewer/internal/service"
 )
 
-const (
-	errorCodeBadRequest api.ErrorResponseErrorCode = "BAD_REQUEST"
-	errorCodeInternal   api.ErrorResponseErrorCode = "INTERNAL_ERROR"
-)
+const errorCodeInternal api.ErrorResponseErrorCode = "INTERNAL_ERROR"
 
 type errorBody struct {
 	Error struct {
@@ -41,6 +39,29 @@ func decodeJSON(r *http.Request, dst interface{}) error {
 	return nil
 }
 
+// decodeJSONRaw decodes the body like decodeJSON and also returns it, for
+// handlers that need more than the generated types can tell.
+func decodeJSONRaw(r *http.Request, dst interface{}) ([]byte, error) {
+	raw, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	return raw, decodeJSON(r, dst)
+}
+
+// isJSONNull reports whether the JSON object body sets field to null, which
+// the generated types can't tell apart from leaving the field out.
+func isJSONNull(body []byte, field string) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	v, ok := fields[field]
+	return ok && string(v) == "null"
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -72,14 +93,21 @@ func writeServiceError(w http.ResponseWriter, err error) {
 
 func statusFromCode(code api.ErrorResponseErrorCode) int {
 	switch code {
-	case api.TEAMEXISTS:
+	case api.TEAMEXISTS,
+		api.BADREQUEST:
 		return http.StatusBadRequest
+	case api.UNAUTHORIZED:
+		return http.StatusUnauthorized
 	case api.NOTFOUND:
 		return http.StatusNotFound
 	case api.PREXISTS,
 		api.PRMERGED,
+		api.PRCLOSED,
 		api.NOTASSIGNED,
-		api.NOCANDIDATE:
+		api.NOCANDIDATE,
+		api.NOTAPPROVED,
+		api.USERINOTHERTEAM,
+		api.TEAMHASOPENPRS:
 		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
@@ -91,5 +119,5 @@ func badRequest(w http.ResponseWriter, err error) {
 	if err != nil {
 		msg = err.Error()
 	}
-	writeAPIError(w, http.StatusBadRequest, errorCodeBadRequest, msg)
+	writeAPIError(w, http.StatusBadRequest, api.BADREQUEST, msg)
 }
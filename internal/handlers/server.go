@@ -8,28 +8,45 @@ import (
 )
 
 type Server struct {
-	svc *service.Service
+	svc          *service.Service
+	githubSecret []byte
+	gitlabToken  string
 }
 
 func NewServer(svc *service.Service) *Server {
 	return &Server{svc: svc}
 }
 
-func (s *Server) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+// SetGitHubWebhookSecret enables /webhooks/github, which accepts only
+// payloads signed with secret.
+func (s *Server) SetGitHubWebhookSecret(secret string) {
+	s.githubSecret = []byte(secret)
+}
+
+// SetGitLabWebhookToken enables /webhooks/gitlab, which accepts only
+// requests carrying token in X-Gitlab-Token.
+func (s *Server) SetGitLabWebhookToken(token string) {
+	s.gitlabToken = token
+}
+
+func (s *Server) PostTeamAdd(w http.ResponseWriter, r *http.Request, params api.PostTeamAddParams) {
 	var body api.PostTeamAddJSONRequestBody
 	if err := decodeJSON(r, &body); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	team, err := s.svc.CreateTeam(r.Context(), api.Team(body))
+	moveMembers := params.MoveMembers != nil && *params.MoveMembers
+
+	team, report, err := s.svc.CreateTeam(r.Context(), api.Team(body), moveMembers)
 	if err != nil {
 		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
-		"team": team,
+		"team":         team,
+		"reassignment": report,
 	})
 }
 
@@ -42,6 +59,139 @@ func (s *Server) GetTeamGet(w http.ResponseWriter, r *http.Request, params api.G
 	writeJSON(w, http.StatusOK, team)
 }
 
+func (s *Server) PostTeamAddMembers(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamAddMembersJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	team, report, err := s.svc.AddTeamMembers(r.Context(), api.Team(body))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"team":         team,
+		"reassignment": report,
+	})
+}
+
+func (s *Server) PostTeamRemoveMembers(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamRemoveMembersJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	team, removed, report, err := s.svc.RemoveTeamMembers(r.Context(), body.TeamName, body.UserIds)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"team":         team,
+		"removed":      removed,
+		"reassignment": report,
+	})
+}
+
+func (s *Server) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamDeleteJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	detached, err := s.svc.DeleteTeam(r.Context(), body.TeamName)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"team_name": body.TeamName,
+		"detached":  detached,
+	})
+}
+
+func (s *Server) GetTeamSettingsGet(w http.ResponseWriter, r *http.Request, params api.GetTeamSettingsGetParams) {
+	settings, err := s.svc.GetTeamSettings(r.Context(), params.TeamName)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (s *Server) PostTeamSettingsSet(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamSettingsSetJSONRequestBody
+	raw, err := decodeJSONRaw(r, &body)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	settings, err := s.svc.SetTeamSettings(r.Context(), body, isJSONNull(raw, "max_open_reviews"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": settings,
+	})
+}
+
+func (s *Server) GetTeamCodeownersGet(w http.ResponseWriter, r *http.Request, params api.GetTeamCodeownersGetParams) {
+	owners, err := s.svc.GetCodeOwners(r.Context(), params.TeamName)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, owners)
+}
+
+func (s *Server) PostTeamCodeownersSet(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamCodeownersSetJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	owners, err := s.svc.SetCodeOwners(r.Context(), body.TeamName, body.Content)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"codeowners": owners,
+	})
+}
+
+func (s *Server) PostTeamDeactivateUsers(w http.ResponseWriter, r *http.Request) {
+	var body api.PostTeamDeactivateUsersJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	users, report, err := s.svc.DeactivateTeamUsers(r.Context(), api.PostTeamDeactivateUsersJSONBody(body))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"team_name":    body.TeamName,
+		"deactivated":  users,
+		"reassignment": report,
+	})
+}
+
 func (s *Server) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
 	var body api.PostUsersSetIsActiveJSONRequestBody
 	if err := decodeJSON(r, &body); err != nil {
@@ -49,12 +199,66 @@ func (s *Server) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.svc.SetUserActive(r.Context(), body.UserId, body.IsActive)
+	user, report, err := s.svc.SetUserActive(r.Context(), body.UserId, body.IsActive)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		api.User
+		Reassignment *api.ReassignmentReport `json:"reassignment,omitempty"`
+	}{user, report})
+}
+
+func (s *Server) PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersSetMaxOpenReviewsJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	user, err := s.svc.SetUserMaxOpenReviews(r.Context(), body.UserId, body.MaxOpenReviews)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+func (s *Server) PostUsersSetLevel(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersSetLevelJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	user, err := s.svc.SetUserLevel(r.Context(), body.UserId, body.Level)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+func (s *Server) PostUsersSetTags(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersSetTagsJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	user, err := s.svc.SetUserTags(r.Context(), body.UserId, body.Tags)
 	if err != nil {
 		writeServiceError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, user)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
 }
 
 func (s *Server) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
@@ -64,15 +268,19 @@ func (s *Server) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := s.svc.CreatePullRequest(r.Context(), body.PullRequestId, body.PullRequestName, body.AuthorId)
+	pr, warnings, err := s.svc.CreatePullRequest(r.Context(), api.PostPullRequestCreateJSONBody(body))
 	if err != nil {
 		writeServiceError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]interface{}{
+	resp := map[string]interface{}{
 		"pr": pr,
-	})
+	}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 func (s *Server) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
@@ -82,7 +290,27 @@ func (s *Server) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := s.svc.MergePullRequest(r.Context(), body.PullRequestId)
+	force := body.Force != nil && *body.Force
+
+	pr, err := s.svc.MergePullRequest(r.Context(), body.PullRequestId, force)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+func (s *Server) PostPullRequestClose(w http.ResponseWriter, r *http.Request) {
+	var body api.PostPullRequestCloseJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	pr, err := s.svc.ClosePullRequest(r.Context(), body.PullRequestId)
 	if err != nil {
 		writeServiceError(w, err)
 		return
@@ -93,6 +321,50 @@ func (s *Server) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) PostPullRequestReopen(w http.ResponseWriter, r *http.Request) {
+	var body api.PostPullRequestReopenJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	pr, warnings, err := s.svc.ReopenPullRequest(r.Context(), body.PullRequestId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"pr": pr,
+	}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) PostPullRequestMarkReady(w http.ResponseWriter, r *http.Request) {
+	var body api.PostPullRequestMarkReadyJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	pr, warnings, err := s.svc.MarkPullRequestReady(r.Context(), body.PullRequestId, body.ReviewersCount)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"pr": pr,
+	}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (s *Server) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
 	var body api.PostPullRequestReassignJSONRequestBody
 	if err := decodeJSON(r, &body); err != nil {
@@ -112,8 +384,117 @@ func (s *Server) PostPullRequestReassign(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+func (s *Server) PostPullRequestReview(w http.ResponseWriter, r *http.Request) {
+	var body api.PostPullRequestReviewJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	pr, err := s.svc.SubmitReview(r.Context(), body.PullRequestId, body.ReviewerId, body.Decision)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+func (s *Server) PostUsersMoveTeam(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersMoveTeamJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	user, fromTeam, report, err := s.svc.MoveUserToTeam(r.Context(), api.PostUsersMoveTeamJSONBody(body))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"user":      user,
+		"from_team": fromTeam,
+	}
+	if report != nil {
+		resp["reassignment"] = report
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) GetUsersTeamHistory(w http.ResponseWriter, r *http.Request, params api.GetUsersTeamHistoryParams) {
+	moves, err := s.svc.ListTeamMoves(r.Context(), params.UserId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": params.UserId,
+		"moves":   moves,
+	})
+}
+
+func (s *Server) PostUsersAvailabilityAdd(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersAvailabilityAddJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	window, report, err := s.svc.AddUnavailability(r.Context(), api.PostUsersAvailabilityAddJSONBody(body))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"window": window,
+	}
+	if report != nil {
+		resp["reassignment"] = report
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) GetUsersAvailabilityList(w http.ResponseWriter, r *http.Request, params api.GetUsersAvailabilityListParams) {
+	windows, err := s.svc.ListUnavailability(r.Context(), params.UserId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": params.UserId,
+		"windows": windows,
+	})
+}
+
+func (s *Server) PostUsersAvailabilityDelete(w http.ResponseWriter, r *http.Request) {
+	var body api.PostUsersAvailabilityDeleteJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	window, err := s.svc.DeleteUnavailability(r.Context(), body.Id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"window": window,
+	})
+}
+
 func (s *Server) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params api.GetUsersGetReviewParams) {
-	prs, err := s.svc.ListUserReviewPRs(r.Context(), params.UserId)
+	pendingOnly := params.Pending != nil && *params.Pending
+
+	prs, err := s.svc.ListUserReviewPRs(r.Context(), params.UserId, pendingOnly)
 	if err != nil {
 		writeServiceError(w, err)
 		return
@@ -8,15 +8,22 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/github"
 	"pr-reviewer/internal/migrations"
+	"pr-reviewer/internal/notify"
 	"pr-reviewer/internal/repo"
 	"pr-reviewer/internal/service"
 )
@@ -97,6 +104,42 @@ func TestIntegration_FullFlow(t *testing.T) {
 		"old_user_id":     oldReviewer,
 	})
 
+	approver := reassignResp.PR.AssignedReviewers[0]
+	var reviewed struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/review", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-1",
+		"reviewer_id":     approver,
+		"decision":        "APPROVED",
+	}), &reviewed)
+	if reviewed.PR.Reviewers == nil || len(*reviewed.PR.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewer entries, got %+v", reviewed.PR.Reviewers)
+	}
+	first := (*reviewed.PR.Reviewers)[0]
+	if first.UserId != approver || first.Decision == nil || *first.Decision != api.APPROVED || first.DecidedAt == nil {
+		t.Fatalf("expected %s to have approved, got %+v", approver, first)
+	}
+
+	var pending struct {
+		PullRequests []api.PullRequestShort `json:"pull_requests"`
+	}
+	app.decodeResponse(app.getJSON("/users/getReview?pending=true&user_id="+approver, http.StatusOK), &pending)
+	if containsPR(pending.PullRequests, "pr-1", api.PullRequestShortStatusOPEN) {
+		t.Fatalf("approved pr-1 must not be pending for %s: %+v", approver, pending.PullRequests)
+	}
+
+	app.expectAPIError(http.StatusConflict, api.NOTASSIGNED, "/pullRequest/review", map[string]string{
+		"pull_request_id": "pr-1",
+		"reviewer_id":     oldReviewer,
+		"decision":        "COMMENTED",
+	})
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/pullRequest/review", map[string]string{
+		"pull_request_id": "pr-1",
+		"reviewer_id":     approver,
+		"decision":        "LGTM",
+	})
+
 	var merged struct {
 		PR api.PullRequest `json:"pr"`
 	}
@@ -125,6 +168,12 @@ func TestIntegration_FullFlow(t *testing.T) {
 		"old_user_id":     reassignResp.PR.AssignedReviewers[0],
 	})
 
+	app.expectAPIError(http.StatusConflict, api.PRMERGED, "/pullRequest/review", map[string]string{
+		"pull_request_id": "pr-1",
+		"reviewer_id":     approver,
+		"decision":        "COMMENTED",
+	})
+
 	var reviews struct {
 		UserID       string                 `json:"user_id"`
 		PullRequests []api.PullRequestShort `json:"pull_requests"`
@@ -165,7 +214,8 @@ func TestIntegration_FullFlow(t *testing.T) {
 	})
 
 	var prZero struct {
-		PR api.PullRequest `json:"pr"`
+		PR       api.PullRequest `json:"pr"`
+		Warnings []api.Warning   `json:"warnings"`
 	}
 	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
 		"pull_request_id":   "pr-0",
@@ -175,6 +225,9 @@ func TestIntegration_FullFlow(t *testing.T) {
 	if len(prZero.PR.AssignedReviewers) != 0 {
 		t.Fatalf("expected 0 reviewers, got %d", len(prZero.PR.AssignedReviewers))
 	}
+	if len(prZero.Warnings) != 1 || prZero.Warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected NO_CANDIDATE warning, got %+v", prZero.Warnings)
+	}
 
 	app.postJSON("/team/add", http.StatusCreated, map[string]any{
 		"team_name": "pair",
@@ -222,6 +275,235 @@ func TestIntegration_FullFlow(t *testing.T) {
 		t.Fatalf("expected no pull requests for unknown user: %+v", emptyReviews.PullRequests)
 	}
 
+	var settings api.TeamSettings
+	app.decodeResponse(app.getJSON("/team/settings/get?team_name=backend", http.StatusOK), &settings)
+	if settings.ReviewerStrategy != api.LeastLoaded {
+		t.Fatalf("expected default least_loaded strategy, got %s", settings.ReviewerStrategy)
+	}
+
+	var updatedSettings struct {
+		Settings api.TeamSettings `json:"settings"`
+	}
+	app.decodeResponse(app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "backend",
+		"reviewer_strategy": "weighted",
+		"reviewer_weights":  map[string]int{"u2": 0},
+	}), &updatedSettings)
+	if updatedSettings.Settings.ReviewerStrategy != api.Weighted {
+		t.Fatalf("expected weighted strategy, got %s", updatedSettings.Settings.ReviewerStrategy)
+	}
+
+	var weightedPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-weighted",
+		"pull_request_name": "Weighted",
+		"author_id":         "u1",
+	}), &weightedPR)
+	if len(weightedPR.PR.AssignedReviewers) != 2 || containsID(weightedPR.PR.AssignedReviewers, "u2") {
+		t.Fatalf("expected u3 and u4 as reviewers, got %v", weightedPR.PR.AssignedReviewers)
+	}
+
+	var closed struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/close", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-weighted",
+	}), &closed)
+	if closed.PR.Status != api.PullRequestStatusCLOSED || closed.PR.ClosedAt == nil {
+		t.Fatalf("expected CLOSED PR with closedAt, got %+v", closed.PR)
+	}
+	app.postJSON("/pullRequest/close", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-weighted",
+	})
+
+	closedReviewer := closed.PR.AssignedReviewers[0]
+	var closedReviews struct {
+		PullRequests []api.PullRequestShort `json:"pull_requests"`
+	}
+	app.decodeResponse(app.getJSON("/users/getReview?user_id="+closedReviewer, http.StatusOK), &closedReviews)
+	for _, pr := range closedReviews.PullRequests {
+		if pr.PullRequestId == "pr-weighted" {
+			t.Fatalf("closed PR must not be listed for %s: %+v", closedReviewer, closedReviews.PullRequests)
+		}
+	}
+
+	app.expectAPIError(http.StatusConflict, api.PRCLOSED, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-weighted",
+		"old_user_id":     closedReviewer,
+	})
+	app.expectAPIError(http.StatusConflict, api.PRCLOSED, "/pullRequest/merge", map[string]string{
+		"pull_request_id": "pr-weighted",
+	})
+	app.expectAPIError(http.StatusConflict, api.PRMERGED, "/pullRequest/close", map[string]string{
+		"pull_request_id": "pr-1",
+	})
+
+	var reopened struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/reopen", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-weighted",
+	}), &reopened)
+	if reopened.PR.Status != api.PullRequestStatusOPEN || reopened.PR.ClosedAt != nil {
+		t.Fatalf("expected reopened PR, got %+v", reopened.PR)
+	}
+
+	var threeReviewers struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-three",
+		"pull_request_name": "Three reviewers",
+		"author_id":         "u2",
+		"reviewers_count":   3,
+	}), &threeReviewers)
+	if len(threeReviewers.PR.AssignedReviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %v", threeReviewers.PR.AssignedReviewers)
+	}
+
+	var draftPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-draft",
+		"pull_request_name": "Draft",
+		"author_id":         "u3",
+		"draft":             true,
+	}), &draftPR)
+	if len(draftPR.PR.AssignedReviewers) != 0 || draftPR.PR.Draft == nil || !*draftPR.PR.Draft {
+		t.Fatalf("expected draft without reviewers, got %+v", draftPR.PR)
+	}
+
+	var readyPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/markReady", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-draft",
+	}), &readyPR)
+	if len(readyPR.PR.AssignedReviewers) != 2 || readyPR.PR.Draft == nil || *readyPR.PR.Draft {
+		t.Fatalf("expected ready PR with 2 reviewers, got %+v", readyPR.PR)
+	}
+	app.postJSON("/pullRequest/markReady", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-draft",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/pullRequest/markReady", map[string]string{
+		"pull_request_id": "pr-missing",
+	})
+
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "backend",
+		"reviewer_strategy": "random",
+	})
+	var deactivated struct {
+		api.User
+		Reassignment api.ReassignmentReport `json:"reassignment"`
+	}
+	app.decodeResponse(app.postJSON("/users/setIsActive", http.StatusOK, map[string]any{
+		"user_id":   "u4",
+		"is_active": false,
+	}), &deactivated)
+	if deactivated.IsActive {
+		t.Fatalf("expected u4 to become inactive, got %+v", deactivated.User)
+	}
+	for _, h := range deactivated.Reassignment.Reassigned {
+		if h.OldReviewerId != "u4" || h.NewReviewerId == nil || *h.NewReviewerId != "u2" {
+			t.Fatalf("expected u4's reviews to go to u2, got %+v", h)
+		}
+	}
+	if len(deactivated.Reassignment.Unassigned) != 1 || deactivated.Reassignment.Unassigned[0].PullRequestId != "pr-three" {
+		t.Fatalf("expected only pr-three to stay unassigned, got %+v", deactivated.Reassignment)
+	}
+	var afterHandoff struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/markReady", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-draft",
+	}), &afterHandoff)
+	if containsID(afterHandoff.PR.AssignedReviewers, "u4") {
+		t.Fatalf("u4 must be handed off from pr-draft, got %v", afterHandoff.PR.AssignedReviewers)
+	}
+	app.postJSON("/users/setIsActive", http.StatusOK, map[string]any{
+		"user_id":   "u4",
+		"is_active": true,
+	})
+
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/deactivateUsers", map[string]any{
+		"team_name": "backend",
+		"user_ids":  []string{"u3", "pair-2"},
+	})
+	app.decodeResponse(app.getJSON("/team/get?team_name=backend", http.StatusOK), &backend)
+	for _, m := range backend.Members {
+		if !m.IsActive {
+			t.Fatalf("failed bulk deactivation must not change anyone, got %+v", backend.Members)
+		}
+	}
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/team/deactivateUsers", map[string]any{
+		"team_name": "backend",
+		"user_ids":  []string{},
+	})
+
+	var bulk struct {
+		TeamName     string                 `json:"team_name"`
+		Deactivated  []api.User             `json:"deactivated"`
+		Reassignment api.ReassignmentReport `json:"reassignment"`
+	}
+	app.decodeResponse(app.postJSON("/team/deactivateUsers", http.StatusOK, map[string]any{
+		"team_name":  "backend",
+		"user_ids":   []string{"u1", "u2"},
+		"all_except": true,
+	}), &bulk)
+	if len(bulk.Deactivated) != 2 {
+		t.Fatalf("expected u3 and u4 to be deactivated, got %+v", bulk.Deactivated)
+	}
+	if len(bulk.Reassignment.Reassigned) != 0 || len(bulk.Reassignment.Unassigned) != 3 {
+		t.Fatalf("expected 3 unassignable slots, got %+v", bulk.Reassignment)
+	}
+	for _, id := range []string{"u3", "u4"} {
+		app.postJSON("/users/setIsActive", http.StatusOK, map[string]any{
+			"user_id":   id,
+			"is_active": true,
+		})
+	}
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/team/settings/set", map[string]any{
+		"team_name":         "backend",
+		"reviewer_strategy": "fastest",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/settings/set", map[string]any{
+		"team_name":         "unknown-team",
+		"reviewer_strategy": "random",
+	})
+	app.expectGETError("/team/settings/get?team_name=unknown-team", http.StatusNotFound, api.NOTFOUND)
+
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":          "pair",
+		"reviewer_strategy":  "random",
+		"required_approvals": 1,
+	})
+	app.expectAPIError(http.StatusConflict, api.NOTAPPROVED, "/pullRequest/merge", map[string]any{
+		"pull_request_id": "pr-1-one",
+	})
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "pair",
+		"reviewer_strategy": "least_loaded",
+	})
+	app.expectAPIError(http.StatusConflict, api.NOTAPPROVED, "/pullRequest/merge", map[string]any{
+		"pull_request_id": "pr-1-one",
+	})
+
+	var forced struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/merge", http.StatusOK, map[string]any{
+		"pull_request_id": "pr-1-one",
+		"force":           true,
+	}), &forced)
+	if forced.PR.Status != api.PullRequestStatusMERGED || forced.PR.ForceMerged == nil || !*forced.PR.ForceMerged {
+		t.Fatalf("expected force-merged PR, got %+v", forced.PR)
+	}
+
 	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/pullRequest/merge", map[string]string{
 		"pull_request_id": "pr-missing",
 	})
@@ -231,7 +513,993 @@ func TestIntegration_FullFlow(t *testing.T) {
 	})
 }
 
+func TestIntegration_TeamMembership(t *testing.T) {
+	app := newIntegrationApp(t)
+	defer app.Close()
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "core",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "Carol", "is_active": true},
+			{"user_id": "c2", "username": "Craig", "is_active": true},
+			{"user_id": "c3", "username": "Chuck", "is_active": true},
+		},
+	})
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "ops",
+		"members": []map[string]any{
+			{"user_id": "o1", "username": "Olivia", "is_active": true},
+		},
+	})
+
+	var added struct {
+		Team api.Team `json:"team"`
+	}
+	app.decodeResponse(app.postJSON("/team/addMembers", http.StatusOK, map[string]any{
+		"team_name": "core",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "Caroline", "is_active": true},
+			{"user_id": "c4", "username": "Cindy", "is_active": true},
+		},
+	}), &added)
+	if len(added.Team.Members) != 4 || added.Team.Members[0].Username != "Caroline" {
+		t.Fatalf("expected 4 members with renamed c1, got %+v", added.Team.Members)
+	}
+
+	app.expectAPIError(http.StatusConflict, api.USERINOTHERTEAM, "/team/addMembers", map[string]any{
+		"team_name": "core",
+		"members": []map[string]any{
+			{"user_id": "c5", "username": "Cody", "is_active": true},
+			{"user_id": "o1", "username": "Olivia", "is_active": true},
+		},
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/addMembers", map[string]any{
+		"team_name": "unknown-team",
+		"members": []map[string]any{
+			{"user_id": "c5", "username": "Cody", "is_active": true},
+		},
+	})
+
+	var core api.Team
+	app.decodeResponse(app.getJSON("/team/get?team_name=core", http.StatusOK), &core)
+	if len(core.Members) != 4 {
+		t.Fatalf("failed addMembers must not change the team, got %+v", core.Members)
+	}
+
+	var created struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-core",
+		"pull_request_name": "Core",
+		"author_id":         "c1",
+	}), &created)
+	removedReviewer := created.PR.AssignedReviewers[0]
+	spare := spareReviewer([]string{"c2", "c3", "c4"}, created.PR.AssignedReviewers)
+
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/removeMembers", map[string]any{
+		"team_name": "core",
+		"user_ids":  []string{removedReviewer, "o1"},
+	})
+	app.expectAPIError(http.StatusConflict, api.TEAMHASOPENPRS, "/team/removeMembers", map[string]any{
+		"team_name": "core",
+		"user_ids":  []string{"c1"},
+	})
+
+	var removed struct {
+		Team         api.Team               `json:"team"`
+		Removed      []api.User             `json:"removed"`
+		Reassignment api.ReassignmentReport `json:"reassignment"`
+	}
+	app.decodeResponse(app.postJSON("/team/removeMembers", http.StatusOK, map[string]any{
+		"team_name": "core",
+		"user_ids":  []string{removedReviewer},
+	}), &removed)
+	if len(removed.Team.Members) != 3 || len(removed.Removed) != 1 || removed.Removed[0].IsActive {
+		t.Fatalf("expected %s to be detached and inactive, got %+v", removedReviewer, removed)
+	}
+	if len(removed.Reassignment.Reassigned) != 1 || *removed.Reassignment.Reassigned[0].NewReviewerId != spare {
+		t.Fatalf("expected the review to go to %s, got %+v", spare, removed.Reassignment)
+	}
+
+	app.expectAPIError(http.StatusConflict, api.TEAMHASOPENPRS, "/team/delete", map[string]string{
+		"team_name": "core",
+	})
+	app.postJSON("/pullRequest/merge", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-core",
+	})
+
+	var deleted struct {
+		TeamName string     `json:"team_name"`
+		Detached []api.User `json:"detached"`
+	}
+	app.decodeResponse(app.postJSON("/team/delete", http.StatusOK, map[string]string{
+		"team_name": "core",
+	}), &deleted)
+	if len(deleted.Detached) != 3 {
+		t.Fatalf("expected 3 detached members, got %+v", deleted.Detached)
+	}
+	app.expectGETError("/team/get?team_name=core", http.StatusNotFound, api.NOTFOUND)
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/delete", map[string]string{
+		"team_name": "core",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-teamless",
+		"pull_request_name": "Teamless",
+		"author_id":         "c1",
+	})
+
+	var ops struct {
+		Team api.Team `json:"team"`
+	}
+	app.decodeResponse(app.postJSON("/team/addMembers", http.StatusOK, map[string]any{
+		"team_name": "ops",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "Caroline", "is_active": true},
+		},
+	}), &ops)
+	if len(ops.Team.Members) != 2 {
+		t.Fatalf("expected teamless c1 to join ops, got %+v", ops.Team.Members)
+	}
+	var adopted struct {
+		Moves []api.TeamMove `json:"moves"`
+	}
+	app.decodeResponse(app.getJSON("/users/teamHistory?user_id=c1", http.StatusOK), &adopted)
+	if n := len(adopted.Moves); n == 0 || adopted.Moves[n-1].FromTeam != nil || adopted.Moves[n-1].ToTeam != "ops" {
+		t.Fatalf("expected c1 joining ops to be recorded in history, got %+v", adopted.Moves)
+	}
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "infra",
+		"members": []map[string]any{
+			{"user_id": "i1", "username": "Ivan", "is_active": true},
+		},
+	})
+	var opsPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-ops",
+		"pull_request_name": "Ops",
+		"author_id":         "c1",
+	}), &opsPR)
+	if len(opsPR.PR.AssignedReviewers) != 1 || opsPR.PR.AssignedReviewers[0] != "o1" {
+		t.Fatalf("expected o1 to review pr-ops, got %v", opsPR.PR.AssignedReviewers)
+	}
+
+	var moved struct {
+		User         api.User               `json:"user"`
+		FromTeam     string                 `json:"from_team"`
+		Reassignment api.ReassignmentReport `json:"reassignment"`
+	}
+	app.decodeResponse(app.postJSON("/users/moveTeam", http.StatusOK, map[string]any{
+		"user_id":          "o1",
+		"team_name":        "infra",
+		"hand_off_reviews": true,
+	}), &moved)
+	if moved.User.TeamName != "infra" || moved.FromTeam != "ops" {
+		t.Fatalf("expected o1 to move from ops to infra, got %+v", moved)
+	}
+	if len(moved.Reassignment.Unassigned) != 1 || moved.Reassignment.Unassigned[0].PullRequestId != "pr-ops" {
+		t.Fatalf("expected pr-ops to have no replacement in ops, got %+v", moved.Reassignment)
+	}
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/users/moveTeam", map[string]any{
+		"user_id":   "ghost",
+		"team_name": "infra",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/users/moveTeam", map[string]any{
+		"user_id":   "o1",
+		"team_name": "unknown-team",
+	})
+
+	app.expectAPIError(http.StatusConflict, api.USERINOTHERTEAM, "/team/add", map[string]any{
+		"team_name": "platform",
+		"members": []map[string]any{
+			{"user_id": "o1", "username": "Olivia", "is_active": true},
+		},
+	})
+	app.expectGETError("/team/get?team_name=platform", http.StatusNotFound, api.NOTFOUND)
+	app.postJSON("/team/add?move_members=true", http.StatusCreated, map[string]any{
+		"team_name": "platform",
+		"members": []map[string]any{
+			{"user_id": "o1", "username": "Olivia", "is_active": true},
+		},
+	})
+
+	var history struct {
+		UserID string         `json:"user_id"`
+		Moves  []api.TeamMove `json:"moves"`
+	}
+	app.decodeResponse(app.getJSON("/users/teamHistory?user_id=o1", http.StatusOK), &history)
+	if len(history.Moves) != 2 || *history.Moves[0].FromTeam != "ops" || history.Moves[1].ToTeam != "platform" {
+		t.Fatalf("expected ops -> infra -> platform history, got %+v", history.Moves)
+	}
+	app.expectGETError("/users/teamHistory?user_id=ghost", http.StatusNotFound, api.NOTFOUND)
+
+	app.postJSON("/team/addMembers", http.StatusOK, map[string]any{
+		"team_name": "infra",
+		"members": []map[string]any{
+			{"user_id": "i2", "username": "Irene", "is_active": true},
+			{"user_id": "i3", "username": "Igor", "is_active": true},
+		},
+	})
+	app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-infra",
+		"pull_request_name": "Infra",
+		"author_id":         "i1",
+	})
+
+	now := time.Now().UTC()
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/users/availability/add", map[string]any{
+		"user_id":   "i2",
+		"starts_at": now,
+		"ends_at":   now.Add(-time.Hour),
+	})
+	var vacation struct {
+		Window       api.UnavailabilityWindow `json:"window"`
+		Reassignment api.ReassignmentReport   `json:"reassignment"`
+	}
+	app.decodeResponse(app.postJSON("/users/availability/add", http.StatusCreated, map[string]any{
+		"user_id":          "i2",
+		"starts_at":        now.Add(-time.Hour),
+		"ends_at":          now.Add(24 * time.Hour),
+		"reason":           "vacation",
+		"reassign_reviews": true,
+	}), &vacation)
+	if vacation.Window.Id == 0 || vacation.Window.Reason == nil || *vacation.Window.Reason != "vacation" {
+		t.Fatalf("expected stored window, got %+v", vacation.Window)
+	}
+	if len(vacation.Reassignment.Unassigned) != 1 || vacation.Reassignment.Unassigned[0].PullRequestId != "pr-infra" {
+		t.Fatalf("expected pr-infra to have no available replacement, got %+v", vacation.Reassignment)
+	}
+
+	app.postJSON("/team/addMembers", http.StatusOK, map[string]any{
+		"team_name": "infra",
+		"members": []map[string]any{
+			{"user_id": "i4", "username": "Inga", "is_active": true},
+		},
+	})
+	var infraPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-infra-2",
+		"pull_request_name": "Infra 2",
+		"author_id":         "i1",
+	}), &infraPR)
+	if len(infraPR.PR.AssignedReviewers) != 2 || containsID(infraPR.PR.AssignedReviewers, "i2") {
+		t.Fatalf("unavailable i2 must not be assigned, got %v", infraPR.PR.AssignedReviewers)
+	}
+
+	var windows struct {
+		Windows []api.UnavailabilityWindow `json:"windows"`
+	}
+	app.decodeResponse(app.getJSON("/users/availability/list?user_id=i2", http.StatusOK), &windows)
+	if len(windows.Windows) != 1 || windows.Windows[0].Id != vacation.Window.Id {
+		t.Fatalf("expected the vacation window, got %+v", windows.Windows)
+	}
+	app.postJSON("/users/availability/delete", http.StatusOK, map[string]any{
+		"id": vacation.Window.Id,
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/users/availability/delete", map[string]any{
+		"id": vacation.Window.Id,
+	})
+	app.expectGETError("/users/availability/list?user_id=ghost", http.StatusNotFound, api.NOTFOUND)
+
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "infra",
+		"reviewer_strategy": "random",
+		"max_open_reviews":  1,
+	})
+	var infraSettings struct {
+		Settings api.TeamSettings `json:"settings"`
+	}
+	app.decodeResponse(app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "infra",
+		"reviewer_strategy": "least_loaded",
+	}), &infraSettings)
+	if limit := infraSettings.Settings.MaxOpenReviews; limit == nil || *limit != 1 {
+		t.Fatalf("expected a strategy update to keep the infra limit of 1, got %+v", infraSettings.Settings)
+	}
+	app.expectAPIError(http.StatusConflict, api.NOCANDIDATE, "/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-infra-3",
+		"pull_request_name": "Infra 3",
+		"author_id":         "i1",
+	})
+	app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-infra-3",
+		"pull_request_name": "Infra 3",
+		"author_id":         "i1",
+		"ignore_capacity":   true,
+	})
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/users/setMaxOpenReviews", map[string]any{
+		"user_id":          "i4",
+		"max_open_reviews": 0,
+	})
+	var limited struct {
+		User api.User `json:"user"`
+	}
+	app.decodeResponse(app.postJSON("/users/setMaxOpenReviews", http.StatusOK, map[string]any{
+		"user_id":          "i4",
+		"max_open_reviews": 5,
+	}), &limited)
+	if limited.User.MaxOpenReviews == nil || *limited.User.MaxOpenReviews != 5 {
+		t.Fatalf("expected i4 limit of 5, got %+v", limited.User)
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-infra-4",
+		"pull_request_name": "Infra 4",
+		"author_id":         "i1",
+	}), &infraPR)
+	if len(infraPR.PR.AssignedReviewers) != 1 || infraPR.PR.AssignedReviewers[0] != "i4" {
+		t.Fatalf("expected only i4 to have capacity left, got %v", infraPR.PR.AssignedReviewers)
+	}
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/users/setMaxOpenReviews", map[string]any{
+		"user_id":          "ghost",
+		"max_open_reviews": nil,
+	})
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "solo",
+		"members": []map[string]any{
+			{"user_id": "s1", "username": "Sam", "is_active": true},
+			{"user_id": "s2", "username": "Sara", "is_active": true},
+		},
+	})
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/team/settings/set", map[string]any{
+		"team_name":         "solo",
+		"reviewer_strategy": "random",
+		"fallback_teams":    []string{"solo"},
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/settings/set", map[string]any{
+		"team_name":         "solo",
+		"reviewer_strategy": "random",
+		"fallback_teams":    []string{"unknown-team", "platform"},
+	})
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "solo",
+		"reviewer_strategy": "random",
+		"fallback_teams":    []string{"platform"},
+	})
+
+	var soloPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]string{
+		"pull_request_id":   "pr-solo",
+		"pull_request_name": "Solo",
+		"author_id":         "s1",
+	}), &soloPR)
+	if got := soloPR.PR.AssignedReviewers; len(got) != 2 || got[0] != "s2" || got[1] != "o1" {
+		t.Fatalf("expected s2 and fallback o1, got %v", got)
+	}
+	if details := *soloPR.PR.Reviewers; *details[0].TeamName != "solo" || *details[1].TeamName != "platform" {
+		t.Fatalf("expected reviewer teams solo and platform, got %+v", details)
+	}
+	app.expectAPIError(http.StatusConflict, api.NOCANDIDATE, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-solo",
+		"old_user_id":     "s2",
+	})
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/team/codeowners/set", map[string]any{
+		"team_name": "solo",
+		"content":   "* s2\n",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/team/codeowners/set", map[string]any{
+		"team_name": "unknown-team",
+		"content":   "* @s2\n",
+	})
+	app.postJSON("/team/codeowners/set", http.StatusOK, map[string]any{
+		"team_name": "solo",
+		"content":   "# owners\n*  @s2\n/docs/  @acme/platform\n",
+	})
+	var owners api.CodeOwners
+	app.decodeResponse(app.getJSON("/team/codeowners/get?team_name=solo", http.StatusOK), &owners)
+	if len(owners.Rules) != 2 || owners.Rules[1].Pattern != "/docs/" || owners.Rules[1].Owners[0] != "@team/platform" {
+		t.Fatalf("expected two parsed rules, got %+v", owners.Rules)
+	}
+
+	var ownedPR struct {
+		PR       api.PullRequest `json:"pr"`
+		Warnings []api.Warning   `json:"warnings"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-docs",
+		"pull_request_name": "Docs",
+		"author_id":         "s1",
+		"reviewers_count":   1,
+		"changed_paths":     []string{"docs/index.md"},
+	}), &ownedPR)
+	if got := ownedPR.PR.AssignedReviewers; len(got) != 1 || got[0] != "o1" || len(ownedPR.Warnings) != 0 {
+		t.Fatalf("expected docs owner o1, got %v %+v", got, ownedPR.Warnings)
+	}
+	if paths := ownedPR.PR.ChangedPaths; paths == nil || len(*paths) != 1 || (*paths)[0] != "docs/index.md" {
+		t.Fatalf("expected changed paths to be stored, got %v", paths)
+	}
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "data",
+		"members": []map[string]any{
+			{"user_id": "d1", "username": "Dana", "is_active": true},
+			{"user_id": "d2", "username": "Dave", "is_active": true, "tags": []string{"go"}},
+			{"user_id": "d3", "username": "Dora", "is_active": true, "tags": []string{"SQL", "infra"}},
+		},
+	})
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "data",
+		"reviewer_strategy": "skill_match",
+		"reviewers_count":   1,
+	})
+
+	var dataPR struct {
+		PR api.PullRequest `json:"pr"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-data",
+		"pull_request_name": "Data",
+		"author_id":         "d1",
+		"labels":            []string{"sql"},
+	}), &dataPR)
+	if got := dataPR.PR.AssignedReviewers; len(got) != 1 || got[0] != "d3" {
+		t.Fatalf("expected sql reviewer d3, got %v", got)
+	}
+	if labels := dataPR.PR.Labels; labels == nil || len(*labels) != 1 || (*labels)[0] != "sql" {
+		t.Fatalf("expected labels to be stored, got %v", labels)
+	}
+
+	var tagged struct {
+		User api.User `json:"user"`
+	}
+	app.decodeResponse(app.postJSON("/users/setTags", http.StatusOK, map[string]any{
+		"user_id": "d2",
+		"tags":    []string{"Go", "migrations", "go"},
+	}), &tagged)
+	if tags := tagged.User.Tags; tags == nil || len(*tags) != 2 || (*tags)[1] != "migrations" {
+		t.Fatalf("expected normalized tags [go migrations], got %v", tags)
+	}
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/users/setTags", map[string]any{
+		"user_id": "ghost",
+		"tags":    []string{"go"},
+	})
+
+	var data api.Team
+	app.decodeResponse(app.getJSON("/team/get?team_name=data", http.StatusOK), &data)
+	if tags := data.Members[2].Tags; tags == nil || len(*tags) != 2 || (*tags)[0] != "sql" {
+		t.Fatalf("expected d3 tags [sql infra], got %v", tags)
+	}
+
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-data-2",
+		"pull_request_name": "Data 2",
+		"author_id":         "d1",
+		"labels":            []string{"migrations"},
+	}), &dataPR)
+	if got := dataPR.PR.AssignedReviewers; len(got) != 1 || got[0] != "d2" {
+		t.Fatalf("expected migrations reviewer d2, got %v", got)
+	}
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/users/setLevel", map[string]any{
+		"user_id": "d2",
+		"level":   "principal",
+	})
+	var leveled struct {
+		User api.User `json:"user"`
+	}
+	app.decodeResponse(app.postJSON("/users/setLevel", http.StatusOK, map[string]any{
+		"user_id": "d2",
+		"level":   "senior",
+	}), &leveled)
+	if leveled.User.Level == nil || *leveled.User.Level != api.Senior {
+		t.Fatalf("expected d2 to be senior, got %+v", leveled.User)
+	}
+	var readded struct {
+		Team api.Team `json:"team"`
+	}
+	app.decodeResponse(app.postJSON("/team/addMembers", http.StatusOK, map[string]any{
+		"team_name": "data",
+		"members": []map[string]any{
+			{"user_id": "d2", "username": "David", "is_active": true},
+		},
+	}), &readded)
+	for _, m := range readded.Team.Members {
+		if m.UserId != "d2" {
+			continue
+		}
+		if m.Username != "David" || m.Level == nil || *m.Level != api.Senior || m.Tags == nil || len(*m.Tags) != 2 {
+			t.Fatalf("expected re-added d2 to keep level and tags, got %+v", m)
+		}
+	}
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "data",
+		"reviewer_strategy": "skill_match",
+		"reviewers_count":   1,
+		"require_senior":    true,
+	})
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-data-3",
+		"pull_request_name": "Data 3",
+		"author_id":         "d1",
+		"labels":            []string{"sql"},
+	}), &dataPR)
+	if got := dataPR.PR.AssignedReviewers; len(got) != 1 || got[0] != "d2" {
+		t.Fatalf("expected senior d2 over the sql match, got %v", got)
+	}
+	app.expectAPIError(http.StatusConflict, api.NOCANDIDATE, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-data-3",
+		"old_user_id":     "d2",
+	})
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/team/settings/set", map[string]any{
+		"team_name":         "data",
+		"reviewer_strategy": "diverse",
+		"diversity_window":  0,
+	})
+	var diverse struct {
+		Settings api.TeamSettings `json:"settings"`
+	}
+	app.decodeResponse(app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "data",
+		"reviewer_strategy": "diverse",
+		"reviewers_count":   1,
+		"diversity_window":  2,
+	}), &diverse)
+	if w := diverse.Settings.DiversityWindow; w == nil || *w != 2 {
+		t.Fatalf("expected diversity_window 2, got %v", w)
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-data-4",
+		"pull_request_name": "Data 4",
+		"author_id":         "d1",
+	}), &dataPR)
+	if got := dataPR.PR.AssignedReviewers; len(got) != 1 || (got[0] != "d2" && got[0] != "d3") {
+		t.Fatalf("expected one of d2 and d3, got %v", got)
+	}
+
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/webhooks/logins/set", map[string]string{
+		"provider": "github",
+		"login":    "octocat",
+		"user_id":  "nobody",
+	})
+	var mapped struct {
+		Login api.ExternalLogin `json:"login"`
+	}
+	app.decodeResponse(app.postJSON("/webhooks/logins/set", http.StatusOK, map[string]string{
+		"provider": "github",
+		"login":    "OctoCat",
+		"user_id":  "d1",
+	}), &mapped)
+	if mapped.Login.Login != "octocat" || mapped.Login.UserId != "d1" {
+		t.Fatalf("expected octocat to map to d1, got %+v", mapped.Login)
+	}
+
+	opened := readWebhookFixture(t, "github", "pull_request_opened.json")
+	app.postGitHubWebhook("pull_request", opened, "sha256=00", http.StatusUnauthorized)
+
+	var hook api.WebhookResult
+	app.decodeResponse(app.postGitHubWebhook("pull_request", opened, "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || hook.PullRequest == nil || hook.PullRequest.PullRequestId != "acme/api#42" {
+		t.Fatalf("expected acme/api#42 to be created, got %+v", hook)
+	}
+	if hook.PullRequest.AuthorId != "d1" || len(hook.PullRequest.AssignedReviewers) != 1 {
+		t.Fatalf("expected d1's pull request with one reviewer, got %+v", hook.PullRequest)
+	}
+	app.decodeResponse(app.postGitHubWebhook("pull_request", opened, "", http.StatusOK), &hook)
+	if hook.Result != api.Ignored {
+		t.Fatalf("expected a redelivery to be ignored, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitHubWebhook("ping", readWebhookFixture(t, "github", "ping.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Ignored {
+		t.Fatalf("expected ping to be ignored, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitHubWebhook("pull_request", readWebhookFixture(t, "github", "pull_request_labeled.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Ignored {
+		t.Fatalf("expected labeled to be ignored, got %+v", hook)
+	}
+
+	app.decodeResponse(app.postGitHubWebhook("pull_request", readWebhookFixture(t, "github", "pull_request_opened_draft.json"), "", http.StatusOK), &hook)
+	if hook.PullRequest == nil || hook.PullRequest.Draft == nil || !*hook.PullRequest.Draft || len(hook.PullRequest.AssignedReviewers) != 0 {
+		t.Fatalf("expected a draft without reviewers, got %+v", hook.PullRequest)
+	}
+	app.decodeResponse(app.postGitHubWebhook("pull_request", readWebhookFixture(t, "github", "pull_request_ready_for_review.json"), "", http.StatusOK), &hook)
+	if hook.PullRequest == nil || len(hook.PullRequest.AssignedReviewers) != 1 {
+		t.Fatalf("expected a reviewer once ready, got %+v", hook.PullRequest)
+	}
+
+	for _, step := range []struct {
+		fixture string
+		status  api.PullRequestStatus
+	}{
+		{"pull_request_closed_merged.json", api.PullRequestStatusMERGED},
+		{"pull_request_closed.json", api.PullRequestStatusCLOSED},
+		{"pull_request_reopened.json", api.PullRequestStatusOPEN},
+	} {
+		app.decodeResponse(app.postGitHubWebhook("pull_request", readWebhookFixture(t, "github", step.fixture), "", http.StatusOK), &hook)
+		if hook.Result != api.Applied || hook.PullRequest.Status != step.status {
+			t.Fatalf("%s: expected status %s, got %+v", step.fixture, step.status, hook)
+		}
+	}
+
+	var logins struct {
+		Logins []api.ExternalLogin `json:"logins"`
+	}
+	app.decodeResponse(app.getJSON("/webhooks/logins/list?provider=github", http.StatusOK), &logins)
+	if len(logins.Logins) != 1 || logins.Logins[0].Login != "octocat" {
+		t.Fatalf("expected the octocat mapping, got %+v", logins.Logins)
+	}
+	app.postJSON("/webhooks/logins/delete", http.StatusOK, map[string]string{
+		"provider": "github",
+		"login":    "octocat",
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/webhooks/logins/delete", map[string]string{
+		"provider": "github",
+		"login":    "octocat",
+	})
+
+	app.postJSON("/webhooks/logins/set", http.StatusOK, map[string]string{
+		"provider": "gitlab",
+		"login":    "jdoe",
+		"user_id":  "d1",
+	})
+	mrOpened := readWebhookFixture(t, "gitlab", "merge_request_open.json")
+	app.postGitLabWebhook(mrOpened, "wrong-token", http.StatusUnauthorized)
+	app.decodeResponse(app.postGitLabWebhook(mrOpened, "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || hook.PullRequest == nil || hook.PullRequest.PullRequestId != "platform/api!7" {
+		t.Fatalf("expected platform/api!7 to be created, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitLabWebhook(mrOpened, "", http.StatusOK), &hook)
+	if hook.Result != api.Ignored {
+		t.Fatalf("expected a redelivery to be ignored, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_update_title.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Ignored {
+		t.Fatalf("expected a title update to be ignored, got %+v", hook)
+	}
+
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_open_draft.json"), "", http.StatusOK), &hook)
+	if hook.PullRequest == nil || hook.PullRequest.Draft == nil || !*hook.PullRequest.Draft {
+		t.Fatalf("expected a draft, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_update_ready.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || len(hook.PullRequest.AssignedReviewers) != 1 {
+		t.Fatalf("expected a reviewer once ready, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_update_ready.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || len(hook.PullRequest.AssignedReviewers) != 1 {
+		t.Fatalf("expected a redelivered ready event to change nothing, got %+v", hook)
+	}
+	reviewers := hook.PullRequest.AssignedReviewers
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_update_draft.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || hook.PullRequest.Draft == nil || !*hook.PullRequest.Draft {
+		t.Fatalf("expected the merge request to be a draft again, got %+v", hook)
+	}
+	app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", "merge_request_update_ready.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || !reflect.DeepEqual(hook.PullRequest.AssignedReviewers, reviewers) {
+		t.Fatalf("expected the reviewers %v to be kept once ready again, got %+v", reviewers, hook)
+	}
+
+	for _, step := range []struct {
+		fixture string
+		status  api.PullRequestStatus
+	}{
+		{"merge_request_merge.json", api.PullRequestStatusMERGED},
+		{"merge_request_merge.json", api.PullRequestStatusMERGED},
+		{"merge_request_close.json", api.PullRequestStatusCLOSED},
+		{"merge_request_reopen.json", api.PullRequestStatusOPEN},
+	} {
+		app.decodeResponse(app.postGitLabWebhook(readWebhookFixture(t, "gitlab", step.fixture), "", http.StatusOK), &hook)
+		if hook.Result != api.Applied || hook.PullRequest.Status != step.status {
+			t.Fatalf("%s: expected status %s, got %+v", step.fixture, step.status, hook)
+		}
+	}
+}
+
+func TestIntegration_ReviewerSync(t *testing.T) {
+	app := newIntegrationApp(t)
+	defer app.Close()
+
+	type call struct {
+		method, path string
+		reviewers    []string
+	}
+	var mu sync.Mutex
+	var calls []call
+	refuse := false
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call{r.Method, r.URL.Path, body.Reviewers})
+		if refuse {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Reviews may only be requested from collaborators."}`))
+		}
+	}))
+	defer gh.Close()
+	app.svc.SetReviewRequester(api.Github, github.NewClient(gh.URL, "t0ken"))
+	takeCalls := func() []call {
+		mu.Lock()
+		defer mu.Unlock()
+		out := calls
+		calls = nil
+		return out
+	}
+	syncJobs := func(want int) {
+		t.Helper()
+		if _, err := app.svc.DispatchOutbox(context.Background()); err != nil {
+			t.Fatalf("dispatch outbox: %v", err)
+		}
+		n, err := app.svc.SyncReviewers(context.Background())
+		if err != nil || n != want {
+			t.Fatalf("expected %d jobs synced, got %d, %v", want, n, err)
+		}
+	}
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "sync",
+		"members": []map[string]any{
+			{"user_id": "s1", "username": "Octo", "is_active": true},
+			{"user_id": "s2", "username": "Hubot", "is_active": true},
+			{"user_id": "s3", "username": "Mona", "is_active": true},
+		},
+	})
+	app.postJSON("/team/settings/set", http.StatusOK, map[string]any{
+		"team_name":         "sync",
+		"reviewer_strategy": "random",
+		"reviewers_count":   1,
+	})
+	logins := map[string]string{"s1": "octocat", "s2": "hubot", "s3": "monalisa"}
+	for userID, login := range logins {
+		app.postJSON("/webhooks/logins/set", http.StatusOK, map[string]string{
+			"provider": "github",
+			"login":    login,
+			"user_id":  userID,
+		})
+	}
+
+	app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-sync-local",
+		"pull_request_name": "Local",
+		"author_id":         "s1",
+	})
+	syncJobs(0)
+
+	var hook api.WebhookResult
+	app.decodeResponse(app.postGitHubWebhook("pull_request", readWebhookFixture(t, "github", "pull_request_opened.json"), "", http.StatusOK), &hook)
+	if hook.Result != api.Applied || hook.PullRequest == nil || len(hook.PullRequest.AssignedReviewers) != 1 {
+		t.Fatalf("expected acme/api#42 with one reviewer, got %+v", hook)
+	}
+	first := hook.PullRequest.AssignedReviewers[0]
+	syncJobs(1)
+	const path = "/repos/acme/api/pulls/42/requested_reviewers"
+	if got, want := takeCalls(), []call{{http.MethodPost, path, []string{logins[first]}}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	syncJobs(0)
+
+	var reassigned struct {
+		PR         api.PullRequest `json:"pr"`
+		ReplacedBy string          `json:"replaced_by"`
+	}
+	app.decodeResponse(app.postJSON("/pullRequest/reassign", http.StatusOK, map[string]string{
+		"pull_request_id": "acme/api#42",
+		"old_user_id":     first,
+	}), &reassigned)
+	syncJobs(1)
+	want := []call{
+		{http.MethodDelete, path, []string{logins[first]}},
+		{http.MethodPost, path, []string{logins[reassigned.ReplacedBy]}},
+	}
+	if got := takeCalls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	mu.Lock()
+	refuse = true
+	mu.Unlock()
+	app.postJSON("/pullRequest/reassign", http.StatusOK, map[string]string{
+		"pull_request_id": "acme/api#42",
+		"old_user_id":     reassigned.ReplacedBy,
+	})
+	syncJobs(1)
+	if got := takeCalls(); len(got) != 1 || got[0].method != http.MethodDelete {
+		t.Fatalf("expected a single refused call, got %+v", got)
+	}
+	syncJobs(0)
+}
+
+func TestIntegration_OutboundWebhooks(t *testing.T) {
+	app := newIntegrationApp(t)
+	defer app.Close()
+
+	type received struct {
+		path, event, delivery string
+		body                  api.WebhookEvent
+	}
+	var mu sync.Mutex
+	var got []received
+	status := http.StatusOK
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(notify.SignatureHeader); sig != github.Sign([]byte("s3cr3t"), body) {
+			t.Errorf("bad signature %q on %s", sig, body)
+		}
+		var ev api.WebhookEvent
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, received{r.URL.Path, r.Header.Get(notify.EventHeader), r.Header.Get(notify.DeliveryHeader), ev})
+		w.WriteHeader(status)
+	}))
+	defer subscriber.Close()
+	app.svc.SetWebhookSender(notify.NewClient(time.Second))
+	setStatus := func(code int) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = code
+	}
+	takeReceived := func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		out := got
+		got = nil
+		return out
+	}
+	deliver := func(want int) {
+		t.Helper()
+		if _, err := app.svc.DispatchOutbox(context.Background()); err != nil {
+			t.Fatalf("dispatch outbox: %v", err)
+		}
+		n, err := app.svc.DeliverWebhooks(context.Background())
+		if err != nil || n != want {
+			t.Fatalf("expected %d deliveries, got %d, %v", want, n, err)
+		}
+	}
+
+	app.expectAPIError(http.StatusBadRequest, api.BADREQUEST, "/webhooks/subscriptions/create", map[string]any{
+		"url":    "not a url",
+		"secret": "s3cr3t",
+	})
+	var created struct {
+		Subscription api.WebhookSubscription `json:"subscription"`
+	}
+	app.decodeResponse(app.postJSON("/webhooks/subscriptions/create", http.StatusCreated, map[string]any{
+		"url":    subscriber.URL + "/merges",
+		"secret": "s3cr3t",
+		"events": []string{"pull_request.created", "pull_request.merged"},
+	}), &created)
+	merges := created.Subscription
+	app.decodeResponse(app.postJSON("/webhooks/subscriptions/create", http.StatusCreated, map[string]any{
+		"url":    subscriber.URL + "/all",
+		"secret": "s3cr3t",
+	}), &created)
+	all := created.Subscription
+	if len(merges.Events) != 2 || len(all.Events) != 0 {
+		t.Fatalf("unexpected subscriptions %+v and %+v", merges, all)
+	}
+	var subs struct {
+		Subscriptions []api.WebhookSubscription `json:"subscriptions"`
+	}
+	app.decodeResponse(app.getJSON("/webhooks/subscriptions/list", http.StatusOK), &subs)
+	if len(subs.Subscriptions) != 2 {
+		t.Fatalf("expected 2 subscriptions, got %+v", subs.Subscriptions)
+	}
+
+	app.postJSON("/team/add", http.StatusCreated, map[string]any{
+		"team_name": "hooks",
+		"members": []map[string]any{
+			{"user_id": "h1", "username": "Hana", "is_active": true},
+			{"user_id": "h2", "username": "Hugo", "is_active": true},
+		},
+	})
+	app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+		"pull_request_id":   "pr-hooks-1",
+		"pull_request_name": "Hooks",
+		"author_id":         "h1",
+	})
+	deliver(2)
+	deliver(0)
+	for _, r := range takeReceived() {
+		if r.event != "pull_request.created" || r.delivery == "" || r.body.Event != api.PullRequestCreated || r.body.PullRequest.PullRequestId != "pr-hooks-1" {
+			t.Fatalf("unexpected delivery %+v", r)
+		}
+	}
+
+	setStatus(http.StatusGone)
+	app.postJSON("/pullRequest/close", http.StatusOK, map[string]string{
+		"pull_request_id": "pr-hooks-1",
+	})
+	deliver(1)
+	if r := takeReceived(); len(r) != 1 || r[0].path != "/all" || r[0].body.PullRequest.Status != api.PullRequestStatusCLOSED {
+		t.Fatalf("expected the close sent to /all only, got %+v", r)
+	}
+
+	var deliveries struct {
+		Deliveries []api.WebhookDelivery `json:"deliveries"`
+	}
+	app.decodeResponse(app.getJSON(fmt.Sprintf("/webhooks/subscriptions/deliveries?subscription_id=%d&status=dead", all.SubscriptionId), http.StatusOK), &deliveries)
+	if len(deliveries.Deliveries) != 1 || deliveries.Deliveries[0].Event != api.PullRequestClosed || deliveries.Deliveries[0].LastError == nil {
+		t.Fatalf("expected the close dead-lettered, got %+v", deliveries.Deliveries)
+	}
+	dead := deliveries.Deliveries[0]
+
+	setStatus(http.StatusOK)
+	var redelivered struct {
+		Delivery api.WebhookDelivery `json:"delivery"`
+	}
+	app.decodeResponse(app.postJSON("/webhooks/subscriptions/redeliver", http.StatusOK, map[string]any{
+		"delivery_id": dead.DeliveryId,
+	}), &redelivered)
+	if redelivered.Delivery.Status != api.Pending || redelivered.Delivery.Attempts != 0 {
+		t.Fatalf("expected the delivery back in the queue, got %+v", redelivered.Delivery)
+	}
+	deliver(1)
+	if r := takeReceived(); len(r) != 1 || r[0].delivery != fmt.Sprint(dead.DeliveryId) {
+		t.Fatalf("expected delivery %d resent, got %+v", dead.DeliveryId, r)
+	}
+	app.decodeResponse(app.getJSON(fmt.Sprintf("/webhooks/subscriptions/deliveries?subscription_id=%d", all.SubscriptionId), http.StatusOK), &deliveries)
+	for _, d := range deliveries.Deliveries {
+		if d.Status != api.Delivered || d.DeliveredAt == nil {
+			t.Fatalf("expected every delivery delivered, got %+v", deliveries.Deliveries)
+		}
+	}
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/webhooks/subscriptions/redeliver", map[string]any{
+		"delivery_id": 999999,
+	})
+
+	for i := 2; i <= 9; i++ {
+		app.postJSON("/pullRequest/create", http.StatusCreated, map[string]any{
+			"pull_request_id":   fmt.Sprintf("pr-hooks-%d", i),
+			"pull_request_name": "Hooks",
+			"author_id":         "h1",
+		})
+	}
+	var dispatched atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n, err := app.svc.DispatchOutbox(context.Background())
+				if err != nil {
+					t.Errorf("dispatch outbox: %v", err)
+				}
+				if err != nil || n == 0 {
+					return
+				}
+				dispatched.Add(int64(n))
+			}
+		}()
+	}
+	wg.Wait()
+	if n := dispatched.Load(); n != 8 {
+		t.Fatalf("expected 8 events dispatched once each, got %d", n)
+	}
+	deliver(16)
+	deliver(0)
+	if r := takeReceived(); len(r) != 16 {
+		t.Fatalf("expected 16 deliveries, got %d", len(r))
+	}
+
+	app.postJSON("/webhooks/subscriptions/delete", http.StatusOK, map[string]any{
+		"subscription_id": merges.SubscriptionId,
+	})
+	app.expectAPIError(http.StatusNotFound, api.NOTFOUND, "/webhooks/subscriptions/delete", map[string]any{
+		"subscription_id": merges.SubscriptionId,
+	})
+	app.expectGETError(fmt.Sprintf("/webhooks/subscriptions/deliveries?subscription_id=%d", merges.SubscriptionId), http.StatusNotFound, api.NOTFOUND)
+}
+
+const integrationWebhookSecret = "integration-secret"
+
 type integrationApp struct {
+	svc     *service.Service
 	client  *http.Client
 	server  *httptest.Server
 	cleanup func()
@@ -259,6 +1527,8 @@ func newIntegrationApp(t *testing.T) *integrationApp {
 	repository := repo.NewRepo(pool)
 	svc := service.NewService(repository)
 	apiServer := NewServer(svc)
+	apiServer.SetGitHubWebhookSecret(integrationWebhookSecret)
+	apiServer.SetGitLabWebhookToken(integrationWebhookSecret)
 
 	httpSrv := httptest.NewServer(api.Handler(apiServer))
 
@@ -269,6 +1539,7 @@ func newIntegrationApp(t *testing.T) *integrationApp {
 	}
 
 	return &integrationApp{
+		svc:     svc,
 		client:  httpSrv.Client(),
 		server:  httpSrv,
 		cleanup: cleanup,
@@ -395,6 +1666,82 @@ func (a *integrationApp) expectGETError(path string, wantStatus int, wantCode ap
 	}
 }
 
+// postGitHubWebhook delivers body as GitHub would, signing it with the
+// test secret unless signature is given.
+func (a *integrationApp) postGitHubWebhook(event string, body []byte, signature string, wantStatus int) []byte {
+	a.t.Helper()
+
+	if signature == "" {
+		signature = github.Sign([]byte(integrationWebhookSecret), body)
+	}
+	req, err := http.NewRequest(http.MethodPost, a.baseURL+"/webhooks/github", bytes.NewReader(body))
+	if err != nil {
+		a.t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.t.Fatalf("POST /webhooks/github: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("read response body: %v", err)
+	}
+
+	if resp.StatusCode != wantStatus {
+		a.t.Fatalf("POST /webhooks/github: expected %d, got %d: %s", wantStatus, resp.StatusCode, data)
+	}
+	return data
+}
+
+// postGitLabWebhook delivers body as GitLab would, with the test token
+// unless token is given.
+func (a *integrationApp) postGitLabWebhook(body []byte, token string, wantStatus int) []byte {
+	a.t.Helper()
+
+	if token == "" {
+		token = integrationWebhookSecret
+	}
+	req, err := http.NewRequest(http.MethodPost, a.baseURL+"/webhooks/gitlab", bytes.NewReader(body))
+	if err != nil {
+		a.t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	req.Header.Set("X-Gitlab-Token", token)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.t.Fatalf("POST /webhooks/gitlab: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("read response body: %v", err)
+	}
+
+	if resp.StatusCode != wantStatus {
+		a.t.Fatalf("POST /webhooks/gitlab: expected %d, got %d: %s", wantStatus, resp.StatusCode, data)
+	}
+	return data
+}
+
+// readWebhookFixture reads a recorded payload of the given Git host.
+func readWebhookFixture(t *testing.T, host, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("..", host, "testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
 func (a *integrationApp) decodeResponse(data []byte, dst any) {
 	a.t.Helper()
 	if err := json.Unmarshal(data, dst); err != nil {
@@ -415,6 +1762,15 @@ func spareReviewer(candidates, assigned []string) string {
 	return ""
 }
 
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
 func containsPR(list []api.PullRequestShort, prID string, status api.PullRequestShortStatus) bool {
 	for _, pr := range list {
 		if pr.PullRequestId == prID && pr.Status == status {
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"pr-reviewer/internal/api"
+)
+
+func (s *Server) PostWebhooksSubscriptionsCreate(w http.ResponseWriter, r *http.Request) {
+	var body api.PostWebhooksSubscriptionsCreateJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	sub, err := s.svc.CreateWebhookSubscription(r.Context(), api.PostWebhooksSubscriptionsCreateJSONBody(body))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"subscription": sub,
+	})
+}
+
+func (s *Server) GetWebhooksSubscriptionsList(w http.ResponseWriter, r *http.Request) {
+	subs, err := s.svc.ListWebhookSubscriptions(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriptions": subs,
+	})
+}
+
+func (s *Server) PostWebhooksSubscriptionsDelete(w http.ResponseWriter, r *http.Request) {
+	var body api.PostWebhooksSubscriptionsDeleteJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	sub, err := s.svc.DeleteWebhookSubscription(r.Context(), body.SubscriptionId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscription": sub,
+	})
+}
+
+func (s *Server) GetWebhooksSubscriptionsDeliveries(w http.ResponseWriter, r *http.Request, params api.GetWebhooksSubscriptionsDeliveriesParams) {
+	deliveries, err := s.svc.ListWebhookDeliveries(r.Context(), params.SubscriptionId, params.Status)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+	})
+}
+
+func (s *Server) PostWebhooksSubscriptionsRedeliver(w http.ResponseWriter, r *http.Request) {
+	var body api.PostWebhooksSubscriptionsRedeliverJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	delivery, err := s.svc.RedeliverWebhook(r.Context(), body.DeliveryId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"delivery": delivery,
+	})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/github"
+	"pr-reviewer/internal/gitlab"
+	"pr-reviewer/internal/service"
+)
+
+// maxWebhookBody is GitHub's own cap on webhook payloads.
+const maxWebhookBody = 25 << 20
+
+func (s *Server) PostWebhooksGithub(w http.ResponseWriter, r *http.Request, params api.PostWebhooksGithubParams) {
+	if len(s.githubSecret) == 0 {
+		writeAPIError(w, http.StatusNotFound, api.NOTFOUND, "GitHub webhooks are not configured")
+		return
+	}
+
+	body, ok := readWebhookBody(w, r)
+	if !ok {
+		return
+	}
+	var signature string
+	if params.XHubSignature256 != nil {
+		signature = *params.XHubSignature256
+	}
+	if err := github.VerifySignature(s.githubSecret, body, signature); err != nil {
+		writeAPIError(w, http.StatusUnauthorized, api.UNAUTHORIZED, err.Error())
+		return
+	}
+
+	var event string
+	if params.XGitHubEvent != nil {
+		event = *params.XGitHubEvent
+	}
+	if event != "pull_request" {
+		reason := "event is not handled"
+		writeJSON(w, http.StatusOK, api.WebhookResult{Event: event, Result: api.Ignored, Reason: &reason})
+		return
+	}
+
+	ev, err := github.ParsePullRequestEvent(body)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	action := service.PullRequestAction(ev.Action)
+	if ev.Action == "closed" && ev.PullRequest.Merged {
+		action = service.PullRequestMerged
+	}
+	result, err := s.svc.ApplyPullRequestEvent(r.Context(), service.PullRequestEvent{
+		Provider:      api.Github,
+		Action:        action,
+		PullRequestID: ev.PullRequestID(),
+		Title:         ev.PullRequest.Title,
+		AuthorLogin:   ev.PullRequest.User.Login,
+		Draft:         ev.PullRequest.Draft,
+		Labels:        ev.Labels(),
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	result.Event = event
+	result.Action = &ev.Action
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) PostWebhooksGitlab(w http.ResponseWriter, r *http.Request, params api.PostWebhooksGitlabParams) {
+	if s.gitlabToken == "" {
+		writeAPIError(w, http.StatusNotFound, api.NOTFOUND, "GitLab webhooks are not configured")
+		return
+	}
+
+	var token string
+	if params.XGitlabToken != nil {
+		token = *params.XGitlabToken
+	}
+	if err := gitlab.VerifyToken(s.gitlabToken, token); err != nil {
+		writeAPIError(w, http.StatusUnauthorized, api.UNAUTHORIZED, err.Error())
+		return
+	}
+
+	body, ok := readWebhookBody(w, r)
+	if !ok {
+		return
+	}
+
+	var event string
+	if params.XGitlabEvent != nil {
+		event = *params.XGitlabEvent
+	}
+	if event != "Merge Request Hook" {
+		reason := "event is not handled"
+		writeJSON(w, http.StatusOK, api.WebhookResult{Event: event, Result: api.Ignored, Reason: &reason})
+		return
+	}
+
+	ev, err := gitlab.ParseMergeRequestEvent(body)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	result, err := s.svc.ApplyPullRequestEvent(r.Context(), service.PullRequestEvent{
+		Provider:      api.Gitlab,
+		Action:        gitlabAction(ev),
+		PullRequestID: ev.PullRequestID(),
+		Title:         ev.ObjectAttributes.Title,
+		AuthorLogin:   ev.User.Username,
+		Draft:         ev.IsDraft(),
+		Labels:        ev.LabelTitles(),
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	result.Event = event
+	result.Action = &ev.ObjectAttributes.Action
+	writeJSON(w, http.StatusOK, result)
+}
+
+// gitlabAction maps a merge request hook onto a pull request action. An
+// update only matters when it toggles the draft state; other updates and
+// unknown actions pass through for the service to ignore.
+func gitlabAction(ev gitlab.MergeRequestEvent) service.PullRequestAction {
+	switch action := ev.ObjectAttributes.Action; action {
+	case "open":
+		return service.PullRequestOpened
+	case "merge":
+		return service.PullRequestMerged
+	case "close":
+		return service.PullRequestClosed
+	case "reopen":
+		return service.PullRequestReopened
+	case "update":
+		changed, draft := ev.DraftChange()
+		switch {
+		case changed && !draft:
+			return service.PullRequestReadyForReview
+		case changed:
+			return service.PullRequestConvertedToDraft
+		}
+		return service.PullRequestAction(action)
+	default:
+		return service.PullRequestAction(action)
+	}
+}
+
+func (s *Server) PostWebhooksLoginsSet(w http.ResponseWriter, r *http.Request) {
+	var body api.PostWebhooksLoginsSetJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	login, err := s.svc.SetExternalLogin(r.Context(), body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"login": login,
+	})
+}
+
+func (s *Server) PostWebhooksLoginsDelete(w http.ResponseWriter, r *http.Request) {
+	var body api.PostWebhooksLoginsDeleteJSONRequestBody
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	login, err := s.svc.DeleteExternalLogin(r.Context(), body.Provider, body.Login)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"login": login,
+	})
+}
+
+func (s *Server) GetWebhooksLoginsList(w http.ResponseWriter, r *http.Request, params api.GetWebhooksLoginsListParams) {
+	logins, err := s.svc.ListExternalLogins(r.Context(), params.Provider)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"logins": logins,
+	})
+}
+
+// readWebhookBody reads the raw payload, which signatures are computed
+// over, and answers 400 itself when that fails.
+func readWebhookBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		badRequest(w, err)
+		return nil, false
+	}
+	return body, true
+}
@@ -9,25 +9,190 @@ CREATE TABLE IF NOT EXISTS users (
   is_active boolean NOT NULL DEFAULT true
 );
 
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS max_open_reviews smallint
+    CHECK (max_open_reviews >= 1),
+  ADD COLUMN IF NOT EXISTS tags text[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS level text NOT NULL DEFAULT 'mid'
+    CHECK (level IN ('junior','mid','senior'));
+
+CREATE TABLE IF NOT EXISTS user_team_history (
+  id        bigserial PRIMARY KEY,
+  user_id   text NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  from_team text,
+  to_team   text NOT NULL,
+  moved_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_team_history_user_id_idx ON user_team_history (user_id, id);
+
+CREATE TABLE IF NOT EXISTS user_unavailability (
+  id         bigserial PRIMARY KEY,
+  user_id    text NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  starts_at  timestamptz NOT NULL,
+  ends_at    timestamptz NOT NULL,
+  reason     text NOT NULL DEFAULT '',
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS user_unavailability_user_id_idx ON user_unavailability (user_id, ends_at);
+
+ALTER TABLE user_unavailability
+  ADD COLUMN IF NOT EXISTS handoff_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS user_unavailability_handoff_at_idx
+  ON user_unavailability (handoff_at) WHERE handoff_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS team_settings (
+  team_name         text PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+  reviewer_strategy text NOT NULL
+    CHECK (reviewer_strategy IN ('random','round_robin','least_loaded','weighted')),
+  reviewer_weights  jsonb NOT NULL DEFAULT '{}'::jsonb
+);
+
+ALTER TABLE team_settings
+  ADD COLUMN IF NOT EXISTS reviewers_count smallint NOT NULL DEFAULT 2
+    CHECK (reviewers_count >= 0),
+  ADD COLUMN IF NOT EXISTS required_approvals smallint NOT NULL DEFAULT 0
+    CHECK (required_approvals >= 0),
+  ADD COLUMN IF NOT EXISTS block_on_changes_requested boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS max_open_reviews smallint
+    CHECK (max_open_reviews >= 1),
+  ADD COLUMN IF NOT EXISTS fallback_teams text[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS require_senior boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS junior_shadow boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS diversity_window smallint NOT NULL DEFAULT 5
+    CHECK (diversity_window BETWEEN 1 AND 100);
+
+ALTER TABLE team_settings DROP CONSTRAINT IF EXISTS team_settings_reviewer_strategy_check;
+ALTER TABLE team_settings ADD CONSTRAINT team_settings_reviewer_strategy_check
+  CHECK (reviewer_strategy IN ('random','round_robin','least_loaded','weighted','skill_match','diverse'));
+
+CREATE TABLE IF NOT EXISTS team_codeowners (
+  team_name  text PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+  content    text NOT NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS external_logins (
+  provider text NOT NULL CHECK (provider IN ('github')),
+  login    text NOT NULL,
+  user_id  text NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  PRIMARY KEY (provider, login)
+);
+
+ALTER TABLE external_logins DROP CONSTRAINT IF EXISTS external_logins_provider_check;
+ALTER TABLE external_logins ADD CONSTRAINT external_logins_provider_check
+  CHECK (provider IN ('github','gitlab'));
+
+CREATE TABLE IF NOT EXISTS team_rotation_cursors (
+  team_name    text PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+  last_user_id text NOT NULL DEFAULT ''
+);
+
 CREATE TABLE IF NOT EXISTS pull_requests (
   pull_request_id   text PRIMARY KEY,
   pull_request_name text NOT NULL,
   author_id         text NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
   status            text NOT NULL DEFAULT 'OPEN'
-    CHECK (status IN ('OPEN','MERGED')),
+    CHECK (status IN ('OPEN','MERGED','CLOSED')),
   created_at        timestamptz NOT NULL DEFAULT now(),
   merged_at         timestamptz
 );
 
+ALTER TABLE pull_requests
+  ADD COLUMN IF NOT EXISTS force_merged boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS closed_at timestamptz,
+  ADD COLUMN IF NOT EXISTS is_draft boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS changed_paths text[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS labels text[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS provider text
+    CHECK (provider IN ('github','gitlab'));
+
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check
+  CHECK (status IN ('OPEN','MERGED','CLOSED'));
+
 CREATE TABLE IF NOT EXISTS pr_reviewers (
   pr_id       text NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
-  slot        smallint NOT NULL CHECK (slot IN (1,2)),
+  slot        smallint NOT NULL CHECK (slot >= 1),
   reviewer_id text NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
   assigned_at timestamptz NOT NULL DEFAULT now(),
   PRIMARY KEY (pr_id, slot),
   UNIQUE (pr_id, reviewer_id)
 );
 
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_slot_check;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_slot_check CHECK (slot >= 1);
+
+CREATE INDEX IF NOT EXISTS users_team_name_idx ON users (team_name);
+CREATE INDEX IF NOT EXISTS pr_reviewers_reviewer_id_idx ON pr_reviewers (reviewer_id);
+CREATE INDEX IF NOT EXISTS pull_requests_author_id_idx ON pull_requests (author_id, created_at DESC);
+
+ALTER TABLE pr_reviewers
+  ADD COLUMN IF NOT EXISTS decision text
+    CHECK (decision IN ('APPROVED','CHANGES_REQUESTED','COMMENTED')),
+  ADD COLUMN IF NOT EXISTS decided_at timestamptz,
+  ADD COLUMN IF NOT EXISTS team_name text;
+
+CREATE TABLE IF NOT EXISTS outbox (
+  id              bigserial PRIMARY KEY,
+  topic           text NOT NULL,
+  pull_request_id text NOT NULL,
+  payload         jsonb NOT NULL,
+  created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pull_request_id_idx
+  ON outbox (pull_request_id, id);
+
+CREATE TABLE IF NOT EXISTS reviewer_sync_queue (
+  id              bigserial PRIMARY KEY,
+  pull_request_id text NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+  provider        text NOT NULL,
+  add_ids         text[] NOT NULL DEFAULT '{}',
+  remove_ids      text[] NOT NULL DEFAULT '{}',
+  attempts        integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  last_error      text,
+  failed_at       timestamptz,
+  created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS reviewer_sync_queue_due_idx
+  ON reviewer_sync_queue (next_attempt_at) WHERE failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS reviewer_sync_queue_pull_request_id_idx
+  ON reviewer_sync_queue (pull_request_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+  id         bigserial PRIMARY KEY,
+  url        text NOT NULL,
+  secret     text NOT NULL,
+  events     text[] NOT NULL DEFAULT '{}',
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id              bigserial PRIMARY KEY,
+  subscription_id bigint NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  event           text NOT NULL,
+  payload         jsonb NOT NULL,
+  status          text NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending','delivered','dead')),
+  attempts        integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  last_error      text,
+  created_at      timestamptz NOT NULL DEFAULT now(),
+  delivered_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+  ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_id_idx
+  ON webhook_deliveries (subscription_id, id DESC);
+
 CREATE OR REPLACE FUNCTION prevent_reviewers_change_on_merged()
 RETURNS trigger AS $$
 BEGIN
@@ -35,9 +200,9 @@ BEGIN
     SELECT 1
     FROM pull_requests pr
     WHERE pr.pull_request_id = COALESCE(NEW.pr_id, OLD.pr_id)
-      AND pr.status = 'MERGED'
+      AND pr.status IN ('MERGED','CLOSED')
   ) THEN
-    RAISE EXCEPTION 'Cannot modify reviewers for merged PR'
+    RAISE EXCEPTION 'Cannot modify reviewers for merged or closed PR'
       USING ERRCODE = '55000';
   END IF;
 
@@ -0,0 +1,77 @@
+// Package notify sends signed webhook events to subscribers.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pr-reviewer/internal/github"
+)
+
+const (
+	EventHeader     = "X-PR-Reviewer-Event"
+	DeliveryHeader  = "X-PR-Reviewer-Delivery"
+	SignatureHeader = "X-PR-Reviewer-Signature-256"
+)
+
+// Message is one delivery of an event to a subscriber.
+type Message struct {
+	DeliveryID int64
+	Event      string
+	Payload    []byte
+}
+
+// Client posts messages to subscriber URLs.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a client that gives up on a subscriber after timeout.
+func NewClient(timeout time.Duration) *Client {
+	return &Client{http: &http.Client{Timeout: timeout}}
+}
+
+// StatusError is a non-2xx response of a subscriber.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("subscriber responded %d", e.StatusCode)
+}
+
+// Temporary reports whether the subscriber may accept the message later.
+// Other client errors mean it never will.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Send posts m to url signed with secret. Any 2xx response is success.
+func (c *Client) Send(ctx context.Context, url, secret string, m Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(m.Payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pr-reviewer")
+	req.Header.Set(EventHeader, m.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(m.DeliveryID, 10))
+	req.Header.Set(SignatureHeader, github.Sign([]byte(secret), m.Payload))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
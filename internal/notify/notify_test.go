@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-reviewer/internal/github"
+)
+
+func TestClient_Send(t *testing.T) {
+	payload := []byte(`{"event":"pull_request.created"}`)
+	var got http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := NewClient(time.Second).Send(context.Background(), srv.URL, "s3cr3t", Message{
+		DeliveryID: 17,
+		Event:      "pull_request.created",
+		Payload:    payload,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != string(payload) {
+		t.Fatalf("expected payload %s, got %s", payload, body)
+	}
+	if got.Get(EventHeader) != "pull_request.created" || got.Get(DeliveryHeader) != "17" {
+		t.Fatalf("unexpected headers %v", got)
+	}
+	if sig := got.Get(SignatureHeader); sig != github.Sign([]byte("s3cr3t"), payload) {
+		t.Fatalf("unexpected signature %q", sig)
+	}
+}
+
+func TestClient_SendErrors(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+		http.StatusGone:                false,
+		http.StatusRequestTimeout:      true,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	}
+	for status, temporary := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		err := NewClient(time.Second).Send(context.Background(), srv.URL, "s3cr3t", Message{Event: "pull_request.merged", Payload: []byte(`{}`)})
+		srv.Close()
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != status || statusErr.Temporary() != temporary {
+			t.Errorf("%d: expected a StatusError (temporary %v), got %v", status, temporary, err)
+		}
+	}
+}
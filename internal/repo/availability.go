@@ -0,0 +1,234 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// AddUnavailability stores a window during which the user must not be
+// picked as a reviewer. With a non-nil plan the user's open reviews are
+// handed over to teammates in the same transaction; a window with HandoffAt
+// set has them handed over later by HandOffDueWindow.
+func (r *Repo) AddUnavailability(ctx context.Context, window api.UnavailabilityWindow, plan HandoffPlan) (api.UnavailabilityWindow, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.UnavailabilityWindow{}, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamName string
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1`,
+		window.UserId,
+	).Scan(&teamName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.UnavailabilityWindow{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return api.UnavailabilityWindow{}, nil, fmt.Errorf("get user team: %w", err)
+	}
+
+	handOff := plan != nil && teamName != ""
+	if handOff {
+		if err := lockTeamTx(ctx, tx, teamName); err != nil {
+			return api.UnavailabilityWindow{}, nil, err
+		}
+	}
+
+	reason := ""
+	if window.Reason != nil {
+		reason = *window.Reason
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason, handoff_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id`,
+		window.UserId, window.StartsAt, window.EndsAt, reason, window.HandoffAt,
+	).Scan(&window.Id); err != nil {
+		return api.UnavailabilityWindow{}, nil, fmt.Errorf("insert unavailability: %w", err)
+	}
+
+	var slots []ReviewSlot
+	if handOff {
+		slots, err = handOffReviewsTx(ctx, tx, teamName, []string{window.UserId}, plan)
+		if err != nil {
+			return api.UnavailabilityWindow{}, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.UnavailabilityWindow{}, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return window, slots, nil
+}
+
+func (r *Repo) ListUnavailability(ctx context.Context, userID string) ([]api.UnavailabilityWindow, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`,
+		userID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check user: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, starts_at, ends_at, reason, handoff_at
+		   FROM user_unavailability
+		  WHERE user_id = $1
+		  ORDER BY starts_at, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select unavailability: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []api.UnavailabilityWindow{}
+	for rows.Next() {
+		w, err := scanUnavailability(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return windows, nil
+}
+
+func (r *Repo) DeleteUnavailability(ctx context.Context, id int64) (api.UnavailabilityWindow, error) {
+	w, err := scanUnavailability(r.pool.QueryRow(ctx,
+		`DELETE FROM user_unavailability
+		  WHERE id = $1
+		  RETURNING id, user_id, starts_at, ends_at, reason, handoff_at`,
+		id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.UnavailabilityWindow{}, ErrNotFound
+	}
+	return w, err
+}
+
+// DueHandoff is an unavailability window whose scheduled handoff is due.
+// TeamName is the user's current team, empty for a user without one.
+type DueHandoff struct {
+	WindowID int64
+	UserID   string
+	TeamName string
+}
+
+// ListDueHandoffs returns up to limit windows whose handoff time has
+// passed, oldest first.
+func (r *Repo) ListDueHandoffs(ctx context.Context, limit int) ([]DueHandoff, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT w.id, w.user_id, COALESCE(u.team_name, '')
+		   FROM user_unavailability w
+		   JOIN users u ON u.user_id = w.user_id
+		  WHERE w.handoff_at <= now()
+		  ORDER BY w.handoff_at, w.id
+		  LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select due handoffs: %w", err)
+	}
+	defer rows.Close()
+
+	var due []DueHandoff
+	for rows.Next() {
+		var d DueHandoff
+		if err := rows.Scan(&d.WindowID, &d.UserID, &d.TeamName); err != nil {
+			return nil, fmt.Errorf("scan due handoff: %w", err)
+		}
+		due = append(due, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return due, nil
+}
+
+// HandOffDueWindow clears the window's scheduled handoff and hands the
+// user's open reviews over to teammates through plan, which was prepared
+// for teamName. Nothing is handed over when the user has no team or the
+// window has already ended. If the user has moved to another team since,
+// the handoff stays scheduled and ErrNotTeamMember is returned so that it
+// can be retried with a plan for the new team. A window that is gone or
+// already handed off yields no slots.
+func (r *Repo) HandOffDueWindow(ctx context.Context, windowID int64, teamName string, plan HandoffPlan) ([]ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if teamName != "" {
+		if err := lockTeamTx(ctx, tx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	var userID, currentTeam string
+	var ended bool
+	err = tx.QueryRow(ctx,
+		`SELECT w.user_id, COALESCE(u.team_name, ''), w.ends_at <= now()
+		   FROM user_unavailability w
+		   JOIN users u ON u.user_id = w.user_id
+		  WHERE w.id = $1
+		    AND w.handoff_at IS NOT NULL
+		    FOR UPDATE OF w`,
+		windowID,
+	).Scan(&userID, &currentTeam, &ended)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock window: %w", err)
+	}
+	if currentTeam != teamName {
+		return nil, ErrNotTeamMember
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE user_unavailability SET handoff_at = NULL WHERE id = $1`,
+		windowID,
+	); err != nil {
+		return nil, fmt.Errorf("clear handoff: %w", err)
+	}
+
+	var slots []ReviewSlot
+	if teamName != "" && !ended {
+		if slots, err = handOffReviewsTx(ctx, tx, teamName, []string{userID}, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return slots, nil
+}
+
+func scanUnavailability(row pgx.Row) (api.UnavailabilityWindow, error) {
+	var w api.UnavailabilityWindow
+	var reason string
+	if err := row.Scan(&w.Id, &w.UserId, &w.StartsAt, &w.EndsAt, &reason, &w.HandoffAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return api.UnavailabilityWindow{}, err
+		}
+		return api.UnavailabilityWindow{}, fmt.Errorf("scan unavailability: %w", err)
+	}
+	if reason != "" {
+		w.Reason = &reason
+	}
+	return w, nil
+}
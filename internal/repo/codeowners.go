@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// GetCodeOwners returns the team's ownership file, with empty content and
+// no updated_at when none has been uploaded.
+func (r *Repo) GetCodeOwners(ctx context.Context, teamName string) (api.CodeOwners, error) {
+	owners := api.CodeOwners{TeamName: teamName}
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(c.content, ''), c.updated_at
+		   FROM teams t
+		   LEFT JOIN team_codeowners c ON c.team_name = t.team_name
+		  WHERE t.team_name = $1`,
+		teamName,
+	).Scan(&owners.Content, &owners.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.CodeOwners{}, ErrNotFound
+	}
+	if err != nil {
+		return api.CodeOwners{}, fmt.Errorf("get codeowners: %w", err)
+	}
+	return owners, nil
+}
+
+func (r *Repo) SetCodeOwners(ctx context.Context, teamName, content string) (api.CodeOwners, error) {
+	owners := api.CodeOwners{TeamName: teamName}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO team_codeowners (team_name, content)
+		 SELECT team_name, $2
+		   FROM teams
+		  WHERE team_name = $1
+		 ON CONFLICT (team_name) DO UPDATE
+		   SET content = EXCLUDED.content,
+		       updated_at = now()
+		 RETURNING content, updated_at`,
+		teamName, content,
+	).Scan(&owners.Content, &owners.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.CodeOwners{}, ErrNotFound
+	}
+	if err != nil {
+		return api.CodeOwners{}, fmt.Errorf("upsert codeowners: %w", err)
+	}
+	return owners, nil
+}
@@ -0,0 +1,304 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// ReviewSlot is an open review held by a user who is being deactivated or
+// moved out of the team, or by an inactive reviewer of a pull request that
+// is being reopened.
+// ReviewerIDs lists everyone currently assigned to the pull request.
+// OldLevel is the old reviewer's level and OtherLevels are the levels of
+// the active reviewers that stay on the pull request.
+type ReviewSlot struct {
+	PullRequestID string
+	AuthorID      string
+	ReviewerIDs   []string
+	OldReviewerID string
+	OldLevel      api.UserLevel
+	OtherLevels   []api.UserLevel
+	NewReviewerID string
+}
+
+// HandoffPlan receives the team's remaining active and available members
+// and the open review slots of the deactivated users, and fills in
+// NewReviewerID for every slot it can reassign. Slots left empty keep their
+// old reviewer.
+type HandoffPlan func(active []string, slots []ReviewSlot)
+
+// DeactivateUser marks the user inactive and hands their open reviews over
+// to teammates in the same transaction. The team row is locked so that
+// concurrent deactivations in one team never pick each other. A user
+// without a team is only marked inactive.
+func (r *Repo) DeactivateUser(ctx context.Context, userID string, plan HandoffPlan) (api.User, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.User{}, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamName string
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1`,
+		userID,
+	).Scan(&teamName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.User{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return api.User{}, nil, fmt.Errorf("get user team: %w", err)
+	}
+
+	if teamName != "" {
+		if err := lockTeamTx(ctx, tx, teamName); err != nil {
+			return api.User{}, nil, err
+		}
+	}
+
+	user, err := scanUser(tx.QueryRow(ctx,
+		`UPDATE users
+		    SET is_active = false
+		  WHERE user_id = $1
+		  RETURNING `+userColumns,
+		userID,
+	))
+	if err != nil {
+		return api.User{}, nil, fmt.Errorf("update user: %w", err)
+	}
+
+	var slots []ReviewSlot
+	if teamName != "" {
+		if slots, err = handOffReviewsTx(ctx, tx, teamName, []string{userID}, plan); err != nil {
+			return api.User{}, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.User{}, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return user, slots, nil
+}
+
+// DeactivateUsers marks the listed members of team inactive, or every
+// member except them when allExcept is set, and hands their open reviews
+// over in the same transaction. Nothing changes unless every listed user
+// belongs to the team.
+func (r *Repo) DeactivateUsers(ctx context.Context, teamName string, userIDs []string, allExcept bool, plan HandoffPlan) ([]api.User, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockTeamTx(ctx, tx, teamName); err != nil {
+		return nil, nil, err
+	}
+
+	var members int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM users WHERE team_name = $1 AND user_id = ANY($2)`,
+		teamName, userIDs,
+	).Scan(&members); err != nil {
+		return nil, nil, fmt.Errorf("count team members: %w", err)
+	}
+	if members != len(userIDs) {
+		return nil, nil, ErrNotTeamMember
+	}
+
+	rows, err := tx.Query(ctx,
+		`UPDATE users
+		    SET is_active = false
+		  WHERE team_name = $1
+		    AND (user_id = ANY($2)) <> $3
+		  RETURNING `+userColumns,
+		teamName, userIDs, allExcept,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("update users: %w", err)
+	}
+	var users []api.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	deactivated := make([]string, 0, len(users))
+	for _, u := range users {
+		deactivated = append(deactivated, u.UserId)
+	}
+	slots, err := handOffReviewsTx(ctx, tx, teamName, deactivated, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return users, slots, nil
+}
+
+func lockTeamTx(ctx context.Context, tx pgx.Tx, teamName string) error {
+	var locked string
+	err := tx.QueryRow(ctx,
+		`SELECT team_name FROM teams WHERE team_name = $1 FOR UPDATE`,
+		teamName,
+	).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("lock team: %w", err)
+	}
+	return nil
+}
+
+// handOffReviewsTx collects the open review slots of userIDs, lets plan
+// choose replacements among the team's active members and applies them
+// in a single statement.
+func handOffReviewsTx(ctx context.Context, tx pgx.Tx, teamName string, userIDs []string, plan HandoffPlan) ([]ReviewSlot, error) {
+	slots, err := openReviewSlotsTx(ctx, tx, userIDs, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	active, err := availableUsersTx(ctx, tx, "team_name = $1", teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan(active, slots)
+
+	var prIDs, oldIDs, newIDs []string
+	for _, slot := range slots {
+		if slot.NewReviewerID == "" {
+			continue
+		}
+		prIDs = append(prIDs, slot.PullRequestID)
+		oldIDs = append(oldIDs, slot.OldReviewerID)
+		newIDs = append(newIDs, slot.NewReviewerID)
+	}
+	if len(prIDs) == 0 {
+		return slots, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE pr_reviewers r
+		    SET reviewer_id = h.new_id,
+		        team_name = $4,
+		        assigned_at = now(),
+		        decision = NULL,
+		        decided_at = NULL
+		   FROM unnest($1::text[], $2::text[], $3::text[]) AS h(pr_id, old_id, new_id)
+		  WHERE r.pr_id = h.pr_id
+		    AND r.reviewer_id = h.old_id`,
+		prIDs, oldIDs, newIDs, teamName,
+	); err != nil {
+		return nil, fmt.Errorf("reassign reviewers: %w", err)
+	}
+
+	if err := publishReassignmentsTx(ctx, tx, prIDs, oldIDs, newIDs); err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// openReviewSlotsTx returns the slots userIDs hold on open pull requests,
+// or only on prID when it is not empty.
+func openReviewSlotsTx(ctx context.Context, tx pgx.Tx, userIDs []string, prID string) ([]ReviewSlot, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT r.pr_id, pr.author_id, r.reviewer_id, o.level,
+		        (SELECT array_agg(a.reviewer_id ORDER BY a.slot)
+		           FROM pr_reviewers a
+		          WHERE a.pr_id = r.pr_id),
+		        (SELECT COALESCE(array_agg(u.level ORDER BY a.slot), '{}')
+		           FROM pr_reviewers a
+		           JOIN users u ON u.user_id = a.reviewer_id
+		          WHERE a.pr_id = r.pr_id
+		            AND a.reviewer_id <> ALL($1)
+		            AND u.is_active)
+		   FROM pr_reviewers r
+		   JOIN pull_requests pr ON pr.pull_request_id = r.pr_id
+		   JOIN users o ON o.user_id = r.reviewer_id
+		  WHERE r.reviewer_id = ANY($1)
+		    AND ($2::text = '' OR r.pr_id = $2)
+		    AND pr.status = 'OPEN'
+		  ORDER BY r.pr_id, r.slot`,
+		userIDs, prID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []ReviewSlot
+	for rows.Next() {
+		var slot ReviewSlot
+		var oldLevel string
+		var otherLevels []string
+		if err := rows.Scan(&slot.PullRequestID, &slot.AuthorID, &slot.OldReviewerID, &oldLevel, &slot.ReviewerIDs, &otherLevels); err != nil {
+			return nil, fmt.Errorf("scan open review: %w", err)
+		}
+		slot.OldLevel = api.UserLevel(oldLevel)
+		for _, l := range otherLevels {
+			slot.OtherLevels = append(slot.OtherLevels, api.UserLevel(l))
+		}
+		slots = append(slots, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return slots, nil
+}
+
+// availableUsersTx returns the ids of the active users matching cond who
+// are not inside an unavailability window right now.
+func availableUsersTx(ctx context.Context, tx pgx.Tx, cond string, arg interface{}) ([]string, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT user_id
+		   FROM users
+		  WHERE `+cond+`
+		    AND is_active = true
+		    AND NOT EXISTS (
+		      SELECT 1
+		        FROM user_unavailability w
+		       WHERE w.user_id = users.user_id
+		         AND now() >= w.starts_at
+		         AND now() < w.ends_at)
+		  ORDER BY user_id`,
+		arg,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select active users: %w", err)
+	}
+	defer rows.Close()
+
+	var active []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan active user: %w", err)
+		}
+		active = append(active, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return active, nil
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// SetExternalLogin maps a Git host login to the user, replacing any user
+// the login was mapped to before.
+func (r *Repo) SetExternalLogin(ctx context.Context, login api.ExternalLogin) (api.ExternalLogin, error) {
+	var saved api.ExternalLogin
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO external_logins (provider, login, user_id)
+		 SELECT $1, $2, user_id
+		   FROM users
+		  WHERE user_id = $3
+		 ON CONFLICT (provider, login) DO UPDATE
+		   SET user_id = EXCLUDED.user_id
+		 RETURNING provider, login, user_id`,
+		login.Provider, login.Login, login.UserId,
+	).Scan(&saved.Provider, &saved.Login, &saved.UserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.ExternalLogin{}, ErrUserNotFound
+	}
+	if err != nil {
+		return api.ExternalLogin{}, fmt.Errorf("upsert external login: %w", err)
+	}
+	return saved, nil
+}
+
+func (r *Repo) DeleteExternalLogin(ctx context.Context, provider api.GitProvider, login string) (api.ExternalLogin, error) {
+	var deleted api.ExternalLogin
+	err := r.pool.QueryRow(ctx,
+		`DELETE FROM external_logins
+		  WHERE provider = $1
+		    AND login = $2
+		  RETURNING provider, login, user_id`,
+		provider, login,
+	).Scan(&deleted.Provider, &deleted.Login, &deleted.UserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.ExternalLogin{}, ErrNotFound
+	}
+	if err != nil {
+		return api.ExternalLogin{}, fmt.Errorf("delete external login: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListExternalLogins returns the mappings of provider, or of every
+// provider when it is empty.
+func (r *Repo) ListExternalLogins(ctx context.Context, provider api.GitProvider) ([]api.ExternalLogin, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT provider, login, user_id
+		   FROM external_logins
+		  WHERE $1 = '' OR provider = $1
+		  ORDER BY provider, login`,
+		string(provider),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select external logins: %w", err)
+	}
+	defer rows.Close()
+
+	logins := []api.ExternalLogin{}
+	for rows.Next() {
+		var l api.ExternalLogin
+		if err := rows.Scan(&l.Provider, &l.Login, &l.UserId); err != nil {
+			return nil, fmt.Errorf("scan external login: %w", err)
+		}
+		logins = append(logins, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return logins, nil
+}
+
+// ExternalLoginUser returns the id of the user the login is mapped to.
+func (r *Repo) ExternalLoginUser(ctx context.Context, provider api.GitProvider, login string) (string, error) {
+	var userID string
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id
+		   FROM external_logins
+		  WHERE provider = $1
+		    AND login = $2`,
+		provider, login,
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get external login: %w", err)
+	}
+	return userID, nil
+}
+
+// ExternalLoginsOf returns a login of each of the users that has one; a
+// user with several logins gets the first in alphabetical order.
+func (r *Repo) ExternalLoginsOf(ctx context.Context, provider api.GitProvider, userIDs []string) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT ON (user_id) user_id, login
+		   FROM external_logins
+		  WHERE provider = $1
+		    AND user_id = ANY($2)
+		  ORDER BY user_id, login`,
+		provider, userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select external logins: %w", err)
+	}
+	defer rows.Close()
+
+	logins := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, login string
+		if err := rows.Scan(&userID, &login); err != nil {
+			return nil, fmt.Errorf("scan external login: %w", err)
+		}
+		logins[userID] = login
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return logins, nil
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// OutboxMessage is an event recorded in the transaction that caused it.
+type OutboxMessage struct {
+	ID            int64
+	Topic         api.WebhookEventType
+	PullRequestID string
+	Payload       []byte
+}
+
+// OutboxRoute tells DispatchOutbox where a message goes: to the webhook
+// subscribers of its topic when Webhooks is set, and as a reviewer change
+// to the Git host of its pull request when that is one of SyncProviders.
+type OutboxRoute struct {
+	Webhooks      bool
+	SyncAddIDs    []string
+	SyncRemoveIDs []string
+	SyncProviders []api.GitProvider
+}
+
+// publishTx records an event about pr in the outbox of tx, so it is
+// published if and only if tx commits.
+func publishTx(ctx context.Context, tx pgx.Tx, event api.WebhookEventType, pr api.PullRequest, opts ...func(*api.WebhookEvent)) error {
+	ev := api.WebhookEvent{Event: event, OccurredAt: time.Now().UTC(), PullRequest: pr}
+	for _, opt := range opts {
+		opt(&ev)
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", event, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO outbox (topic, pull_request_id, payload)
+		 VALUES ($1, $2, $3)`,
+		string(event), pr.PullRequestId, payload,
+	); err != nil {
+		return fmt.Errorf("insert outbox: %w", err)
+	}
+	return nil
+}
+
+// publishReassignmentsTx records that oldIDs[i]'s review of prIDs[i] was
+// handed over to newIDs[i], for every i. Each pull request is loaded once
+// and the events are encoded like publishTx does, but inserted in one
+// statement so that bulk handoffs don't cost a round trip per slot.
+func publishReassignmentsTx(ctx context.Context, tx pgx.Tx, prIDs, oldIDs, newIDs []string) error {
+	now := time.Now().UTC()
+	prs := map[string]api.PullRequest{}
+	payloads := make([]string, 0, len(prIDs))
+	for i, prID := range prIDs {
+		pr, ok := prs[prID]
+		if !ok {
+			var err error
+			if pr, err = loadPullRequestTx(ctx, tx, prID); err != nil {
+				return err
+			}
+			prs[prID] = pr
+		}
+		payload, err := json.Marshal(api.WebhookEvent{
+			Event:       api.PullRequestReviewerReassigned,
+			OccurredAt:  now,
+			PullRequest: pr,
+			Reassignment: &api.ReviewHandoff{
+				PullRequestId: prID,
+				OldReviewerId: oldIDs[i],
+				NewReviewerId: &newIDs[i],
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("encode %s event: %w", api.PullRequestReviewerReassigned, err)
+		}
+		payloads = append(payloads, string(payload))
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO outbox (topic, pull_request_id, payload)
+		 SELECT $3, e.pr_id, e.payload
+		   FROM unnest($1::text[], $2::jsonb[]) WITH ORDINALITY AS e(pr_id, payload, n)
+		  ORDER BY e.n`,
+		prIDs, payloads, string(api.PullRequestReviewerReassigned),
+	); err != nil {
+		return fmt.Errorf("insert outbox: %w", err)
+	}
+	return nil
+}
+
+// DispatchOutbox takes up to limit messages in order, skipping those
+// other dispatchers hold, and fans each one out as route says in the same
+// transaction that deletes it. A message waits while an older one of the
+// same pull request is held elsewhere, so each pull request's events keep
+// their order. It returns how many messages it dispatched.
+func (r *Repo) DispatchOutbox(ctx context.Context, limit int, route func(OutboxMessage) (OutboxRoute, error)) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx,
+		`WITH batch AS MATERIALIZED (
+		   SELECT id
+		     FROM outbox
+		    ORDER BY id
+		    LIMIT $1
+		      FOR UPDATE SKIP LOCKED
+		 )
+		 SELECT o.id, o.topic, o.pull_request_id, o.payload
+		   FROM outbox o
+		   JOIN batch b ON b.id = o.id
+		  WHERE NOT EXISTS (
+		    SELECT 1
+		      FROM outbox e
+		     WHERE e.pull_request_id = o.pull_request_id
+		       AND e.id < o.id
+		       AND e.id NOT IN (SELECT id FROM batch))
+		  ORDER BY o.id`,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox: %w", err)
+	}
+	var msgs []OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		if err := rows.Scan(&m.ID, &m.Topic, &m.PullRequestID, &m.Payload); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan outbox: %w", err)
+		}
+		msgs = append(msgs, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows err: %w", err)
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(msgs))
+	for _, m := range msgs {
+		rt, err := route(m)
+		if err != nil {
+			return 0, fmt.Errorf("route outbox message %d: %w", m.ID, err)
+		}
+		if rt.Webhooks {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO webhook_deliveries (subscription_id, event, payload)
+				 SELECT id, $1::text, $2::jsonb
+				   FROM webhook_subscriptions
+				  WHERE cardinality(events) = 0
+				     OR $1::text = ANY(events)`,
+				string(m.Topic), m.Payload,
+			); err != nil {
+				return 0, fmt.Errorf("enqueue webhook event: %w", err)
+			}
+		}
+		if len(rt.SyncProviders) > 0 && len(rt.SyncAddIDs)+len(rt.SyncRemoveIDs) > 0 {
+			providers := make([]string, 0, len(rt.SyncProviders))
+			for _, p := range rt.SyncProviders {
+				providers = append(providers, string(p))
+			}
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO reviewer_sync_queue (pull_request_id, provider, add_ids, remove_ids)
+				 SELECT pull_request_id, provider, COALESCE($2, '{}'::text[]), COALESCE($3, '{}'::text[])
+				   FROM pull_requests
+				  WHERE pull_request_id = $1
+				    AND provider = ANY($4)`,
+				m.PullRequestID, rt.SyncAddIDs, rt.SyncRemoveIDs, providers,
+			); err != nil {
+				return 0, fmt.Errorf("enqueue reviewer sync: %w", err)
+			}
+		}
+		ids = append(ids, m.ID)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("delete outbox: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return len(msgs), nil
+}
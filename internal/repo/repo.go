@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -17,6 +18,11 @@ var (
 	ErrTeamExists        = errors.New("team already exists")
 	ErrPullRequestExists = errors.New("pull request already exists")
 	ErrReviewerNotFound  = errors.New("reviewer not found for this PR")
+	ErrNotTeamMember     = errors.New("user is not a member of the team")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserInOtherTeam   = errors.New("user is a member of another team")
+	ErrTeamHasOpenPRs    = errors.New("team members have open pull requests")
+	ErrFallbackNotFound  = errors.New("fallback team not found")
 )
 
 type Repo struct {
@@ -27,10 +33,16 @@ func NewRepo(pool *pgxpool.Pool) *Repo {
 	return &Repo{pool: pool}
 }
 
-func (r *Repo) CreateTeamWithMembers(ctx context.Context, team api.Team) (api.Team, error) {
+// CreateTeamWithMembers creates the team and upserts its members. Members
+// of other teams are moved over, with the move recorded in history, only
+// when moveMembers is set; otherwise ErrUserInOtherTeam is returned. The
+// open reviews of moved members are handed over to their old teams through
+// plans, keyed by old team name; a team without a plan keeps its slots
+// unassigned.
+func (r *Repo) CreateTeamWithMembers(ctx context.Context, team api.Team, moveMembers bool, plans map[string]HandoffPlan) (api.Team, []ReviewSlot, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return api.Team{}, fmt.Errorf("begin tx: %w", err)
+		return api.Team{}, nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
@@ -39,47 +51,96 @@ func (r *Repo) CreateTeamWithMembers(ctx context.Context, team api.Team) (api.Te
 		`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`,
 		team.TeamName,
 	).Scan(&exists); err != nil {
-		return api.Team{}, fmt.Errorf("check team exists: %w", err)
+		return api.Team{}, nil, fmt.Errorf("check team exists: %w", err)
 	}
 	if exists {
-		return api.Team{}, ErrTeamExists
+		return api.Team{}, nil, ErrTeamExists
 	}
 
 	if _, err := tx.Exec(ctx,
 		`INSERT INTO teams (team_name) VALUES ($1)`,
 		team.TeamName,
 	); err != nil {
-		return api.Team{}, fmt.Errorf("insert team: %w", err)
+		return api.Team{}, nil, fmt.Errorf("insert team: %w", err)
 	}
 
+	ids := make([]string, 0, len(team.Members))
 	for _, m := range team.Members {
-		if _, err := tx.Exec(ctx,
-			`INSERT INTO users (user_id, username, team_name, is_active)
-			 VALUES ($1, $2, $3, $4)
-			 ON CONFLICT (user_id) DO UPDATE
-			   SET username = EXCLUDED.username,
-			       team_name = EXCLUDED.team_name,
-			       is_active = EXCLUDED.is_active`,
-			m.UserId, m.Username, team.TeamName, m.IsActive,
-		); err != nil {
-			return api.Team{}, fmt.Errorf("upsert user %s: %w", m.UserId, err)
+		ids = append(ids, m.UserId)
+	}
+	rows, err := tx.Query(ctx,
+		`SELECT user_id, team_name
+		   FROM users
+		  WHERE user_id = ANY($1)
+		    AND team_name IS NOT NULL
+		  ORDER BY user_id`,
+		ids,
+	)
+	if err != nil {
+		return api.Team{}, nil, fmt.Errorf("select member teams: %w", err)
+	}
+	moved := map[string][]string{}
+	for rows.Next() {
+		var userID, fromTeam string
+		if err := rows.Scan(&userID, &fromTeam); err != nil {
+			rows.Close()
+			return api.Team{}, nil, fmt.Errorf("scan member team: %w", err)
 		}
+		moved[fromTeam] = append(moved[fromTeam], userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return api.Team{}, nil, fmt.Errorf("rows err: %w", err)
+	}
+	if len(moved) > 0 && !moveMembers {
+		return api.Team{}, nil, ErrUserInOtherTeam
+	}
+
+	// Lock the old teams in name order so concurrent moves can't deadlock.
+	fromTeams := make([]string, 0, len(moved))
+	for name := range moved {
+		fromTeams = append(fromTeams, name)
+	}
+	sort.Strings(fromTeams)
+	for _, name := range fromTeams {
+		if err := lockTeamTx(ctx, tx, name); err != nil {
+			return api.Team{}, nil, err
+		}
+	}
+
+	for _, m := range team.Members {
+		if err := upsertMemberTx(ctx, tx, team.TeamName, m); err != nil {
+			return api.Team{}, nil, err
+		}
+	}
+
+	var slots []ReviewSlot
+	for _, name := range fromTeams {
+		plan := plans[name]
+		if plan == nil {
+			plan = func([]string, []ReviewSlot) {}
+		}
+		handedOff, err := handOffReviewsTx(ctx, tx, name, moved[name], plan)
+		if err != nil {
+			return api.Team{}, nil, err
+		}
+		slots = append(slots, handedOff...)
 	}
 
 	loaded, err := loadTeamTx(ctx, tx, team.TeamName)
 	if err != nil {
-		return api.Team{}, err
+		return api.Team{}, nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return api.Team{}, fmt.Errorf("commit tx: %w", err)
+		return api.Team{}, nil, fmt.Errorf("commit tx: %w", err)
 	}
-	return loaded, nil
+	return loaded, slots, nil
 }
 
 func loadTeamTx(ctx context.Context, tx pgx.Tx, teamName string) (api.Team, error) {
 	rows, err := tx.Query(ctx,
-		`SELECT user_id, username, is_active
+		`SELECT user_id, username, is_active, max_open_reviews, tags, level
 		   FROM users
 		  WHERE team_name = $1
 		  ORDER BY user_id`,
@@ -92,16 +153,15 @@ func loadTeamTx(ctx context.Context, tx pgx.Tx, teamName string) (api.Team, erro
 
 	var members []api.TeamMember
 	for rows.Next() {
-		var id, username string
-		var active bool
-		if err := rows.Scan(&id, &username, &active); err != nil {
+		var m api.TeamMember
+		var tags []string
+		var level api.UserLevel
+		if err := rows.Scan(&m.UserId, &m.Username, &m.IsActive, &m.MaxOpenReviews, &tags, &level); err != nil {
 			return api.Team{}, fmt.Errorf("scan member: %w", err)
 		}
-		members = append(members, api.TeamMember{
-			UserId:   id,
-			Username: username,
-			IsActive: active,
-		})
+		m.Tags = &tags
+		m.Level = &level
+		members = append(members, m)
 	}
 	if err := rows.Err(); err != nil {
 		return api.Team{}, fmt.Errorf("rows err: %w", err)
@@ -126,7 +186,7 @@ func (r *Repo) GetTeam(ctx context.Context, teamName string) (api.Team, error) {
 	}
 
 	rows, err := r.pool.Query(ctx,
-		`SELECT user_id, username, is_active
+		`SELECT user_id, username, is_active, max_open_reviews, tags, level
 		   FROM users
 		  WHERE team_name = $1
 		  ORDER BY user_id`,
@@ -139,16 +199,15 @@ func (r *Repo) GetTeam(ctx context.Context, teamName string) (api.Team, error) {
 
 	var members []api.TeamMember
 	for rows.Next() {
-		var id, username string
-		var active bool
-		if err := rows.Scan(&id, &username, &active); err != nil {
+		var m api.TeamMember
+		var tags []string
+		var level api.UserLevel
+		if err := rows.Scan(&m.UserId, &m.Username, &m.IsActive, &m.MaxOpenReviews, &tags, &level); err != nil {
 			return api.Team{}, fmt.Errorf("scan member: %w", err)
 		}
-		members = append(members, api.TeamMember{
-			UserId:   id,
-			Username: username,
-			IsActive: active,
-		})
+		m.Tags = &tags
+		m.Level = &level
+		members = append(members, m)
 	}
 	if err := rows.Err(); err != nil {
 		return api.Team{}, fmt.Errorf("rows err: %w", err)
@@ -161,63 +220,127 @@ func (r *Repo) GetTeam(ctx context.Context, teamName string) (api.Team, error) {
 }
 
 func (r *Repo) SetUserActive(ctx context.Context, userID string, isActive bool) (api.User, error) {
-	var id, username, teamName string
-	var active bool
-
-	err := r.pool.QueryRow(ctx,
+	user, err := scanUser(r.pool.QueryRow(ctx,
 		`UPDATE users
 		    SET is_active = $2
 		  WHERE user_id = $1
-		  RETURNING user_id, username, team_name, is_active`,
+		  RETURNING `+userColumns,
 		userID, isActive,
-	).Scan(&id, &username, &teamName, &active)
+	))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return api.User{}, ErrNotFound
 	}
 	if err != nil {
 		return api.User{}, fmt.Errorf("update user: %w", err)
 	}
+	return user, nil
+}
 
-	return api.User{
-		UserId:   id,
-		Username: username,
-		TeamName: teamName,
-		IsActive: active,
-	}, nil
+func (r *Repo) SetUserMaxOpenReviews(ctx context.Context, userID string, limit *int) (api.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx,
+		`UPDATE users
+		    SET max_open_reviews = $2
+		  WHERE user_id = $1
+		  RETURNING `+userColumns,
+		userID, limit,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.User{}, ErrNotFound
+	}
+	if err != nil {
+		return api.User{}, fmt.Errorf("update user: %w", err)
+	}
+	return user, nil
 }
 
-func (r *Repo) GetUser(ctx context.Context, userID string) (api.User, error) {
-	var id, username, teamName string
-	var active bool
+func (r *Repo) SetUserTags(ctx context.Context, userID string, tags []string) (api.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx,
+		`UPDATE users
+		    SET tags = $2
+		  WHERE user_id = $1
+		  RETURNING `+userColumns,
+		userID, tags,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.User{}, ErrNotFound
+	}
+	if err != nil {
+		return api.User{}, fmt.Errorf("update user: %w", err)
+	}
+	return user, nil
+}
 
-	err := r.pool.QueryRow(ctx,
-		`SELECT user_id, username, team_name, is_active
+func (r *Repo) SetUserLevel(ctx context.Context, userID string, level api.UserLevel) (api.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx,
+		`UPDATE users
+		    SET level = $2
+		  WHERE user_id = $1
+		  RETURNING `+userColumns,
+		userID, string(level),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.User{}, ErrNotFound
+	}
+	if err != nil {
+		return api.User{}, fmt.Errorf("update user: %w", err)
+	}
+	return user, nil
+}
+
+func (r *Repo) GetUser(ctx context.Context, userID string) (api.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx,
+		`SELECT `+userColumns+`
 		   FROM users
 		  WHERE user_id = $1`,
 		userID,
-	).Scan(&id, &username, &teamName, &active)
+	))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return api.User{}, ErrNotFound
 	}
 	if err != nil {
 		return api.User{}, fmt.Errorf("get user: %w", err)
 	}
+	return user, nil
+}
 
-	return api.User{
-		UserId:   id,
-		Username: username,
-		TeamName: teamName,
-		IsActive: active,
-	}, nil
+// userColumns lists the users columns scanUser expects, in order.
+const userColumns = `user_id, username, COALESCE(team_name, ''), is_active, max_open_reviews, tags, level`
+
+func scanUser(row pgx.Row) (api.User, error) {
+	var u api.User
+	var tags []string
+	var level api.UserLevel
+	err := row.Scan(&u.UserId, &u.Username, &u.TeamName, &u.IsActive, &u.MaxOpenReviews, &tags, &level)
+	u.Tags = &tags
+	u.Level = &level
+	return u, err
 }
 
+// ListActiveUsersInTeam returns the team's active members that are not
+// inside an unavailability window right now.
 func (r *Repo) ListActiveUsersInTeam(ctx context.Context, teamName string) ([]api.User, error) {
+	return r.listActiveUsers(ctx, "team_name = $1", teamName)
+}
+
+// ListActiveUsers is ListActiveUsersInTeam for the given users, whatever
+// team they are in.
+func (r *Repo) ListActiveUsers(ctx context.Context, userIDs []string) ([]api.User, error) {
+	return r.listActiveUsers(ctx, "user_id = ANY($1)", userIDs)
+}
+
+func (r *Repo) listActiveUsers(ctx context.Context, cond string, arg interface{}) ([]api.User, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT user_id, username, team_name, is_active
+		`SELECT `+userColumns+`
 		   FROM users
-		  WHERE team_name = $1
-		    AND is_active = true`,
-		teamName,
+		  WHERE `+cond+`
+		    AND is_active = true
+		    AND NOT EXISTS (
+		      SELECT 1
+		        FROM user_unavailability w
+		       WHERE w.user_id = users.user_id
+		         AND now() >= w.starts_at
+		         AND now() < w.ends_at)`,
+		arg,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("select active users: %w", err)
@@ -226,17 +349,11 @@ func (r *Repo) ListActiveUsersInTeam(ctx context.Context, teamName string) ([]ap
 
 	var users []api.User
 	for rows.Next() {
-		var id, username, tname string
-		var active bool
-		if err := rows.Scan(&id, &username, &tname, &active); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
-		users = append(users, api.User{
-			UserId:   id,
-			Username: username,
-			TeamName: tname,
-			IsActive: active,
-		})
+		users = append(users, u)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows err: %w", err)
@@ -244,6 +361,71 @@ func (r *Repo) ListActiveUsersInTeam(ctx context.Context, teamName string) ([]ap
 	return users, nil
 }
 
+func (r *Repo) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT r.reviewer_id, COUNT(*)
+		   FROM pr_reviewers r
+		   JOIN pull_requests pr ON pr.pull_request_id = r.pr_id
+		  WHERE r.reviewer_id = ANY($1)
+		    AND pr.status = 'OPEN'
+		    AND NOT pr.is_draft
+		  GROUP BY r.reviewer_id`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan review count: %w", err)
+		}
+		counts[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return counts, nil
+}
+
+// CountRecentReviews counts, for each reviewer, how many of the author's
+// last n pull requests they review.
+func (r *Repo) CountRecentReviews(ctx context.Context, authorID string, n int) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT r.reviewer_id, count(*)
+		   FROM pr_reviewers r
+		   JOIN (SELECT pull_request_id
+		           FROM pull_requests
+		          WHERE author_id = $1
+		          ORDER BY created_at DESC
+		          LIMIT $2) pr ON pr.pull_request_id = r.pr_id
+		  GROUP BY r.reviewer_id`,
+		authorID, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count recent reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, fmt.Errorf("scan count: %w", err)
+		}
+		counts[id] = cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return counts, nil
+}
+
 func (r *Repo) PullRequestExists(ctx context.Context, prID string) (bool, error) {
 	var exists bool
 	if err := r.pool.QueryRow(ctx,
@@ -255,7 +437,35 @@ func (r *Repo) PullRequestExists(ctx context.Context, prID string) (bool, error)
 	return exists, nil
 }
 
-func (r *Repo) CreatePullRequest(ctx context.Context, prID, prName, authorID string, reviewerIDs []string) (api.PullRequest, error) {
+type NewPullRequest struct {
+	ID           string
+	Name         string
+	AuthorID     string
+	Draft        bool
+	ChangedPaths []string
+	Labels       []string
+	// Provider is the Git host the pull request mirrors, if any.
+	Provider api.GitProvider
+}
+
+// Assignment describes the reviewers to put on a pull request. Rotation,
+// when set, picks further reviewers after ReviewerIDs; FallbackIDs come
+// from fallback teams and are assigned last.
+type Assignment struct {
+	ReviewerIDs []string
+	Rotation    *Rotation
+	FallbackIDs []string
+}
+
+// Rotation picks reviewers from a team's persisted round-robin cursor.
+// Next receives the user_id assigned last and returns the reviewers to
+// assign together with the new cursor value.
+type Rotation struct {
+	TeamName string
+	Next     func(cursor string) (reviewerIDs []string, next string)
+}
+
+func (r *Repo) CreatePullRequest(ctx context.Context, pr NewPullRequest, assignment Assignment) (api.PullRequest, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return api.PullRequest{}, fmt.Errorf("begin tx: %w", err)
@@ -263,22 +473,58 @@ func (r *Repo) CreatePullRequest(ctx context.Context, prID, prName, authorID str
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	_, err = tx.Exec(ctx,
-		`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-		 VALUES ($1, $2, $3, 'OPEN')`,
-		prID, prName, authorID,
+		`INSERT INTO pull_requests (
+		   pull_request_id, pull_request_name, author_id, status, is_draft, changed_paths, labels, provider)
+		 VALUES ($1, $2, $3, 'OPEN', $4, COALESCE($5, '{}'::text[]), COALESCE($6, '{}'::text[]), NULLIF($7, ''))`,
+		pr.ID, pr.Name, pr.AuthorID, pr.Draft, pr.ChangedPaths, pr.Labels, string(pr.Provider),
 	)
 	if err != nil {
 		return api.PullRequest{}, fmt.Errorf("insert pr: %w", err)
 	}
 
-	for i, rid := range reviewerIDs {
-		slot := int16(i + 1)
-		if _, err := tx.Exec(ctx,
-			`INSERT INTO pr_reviewers (pr_id, slot, reviewer_id)
-			 VALUES ($1, $2, $3)`,
-			prID, slot, rid,
-		); err != nil {
-			return api.PullRequest{}, fmt.Errorf("insert reviewer: %w", err)
+	if err := assignReviewersTx(ctx, tx, pr.ID, assignment); err != nil {
+		return api.PullRequest{}, err
+	}
+
+	created, err := loadPullRequestTx(ctx, tx, pr.ID)
+	if err != nil {
+		return api.PullRequest{}, err
+	}
+	if err := publishTx(ctx, tx, api.PullRequestCreated, created); err != nil {
+		return api.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.PullRequest{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return created, nil
+}
+
+// MarkPullRequestReady clears the draft flag and assigns reviewers in one
+// transaction. Pull requests that are not open drafts are left unchanged.
+func (r *Repo) MarkPullRequestReady(ctx context.Context, prID string, assignment Assignment) (api.PullRequest, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.PullRequest{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx,
+		`UPDATE pull_requests
+		    SET is_draft = false
+		  WHERE pull_request_id = $1
+		    AND is_draft
+		    AND status = 'OPEN'`,
+		prID,
+	)
+	if err != nil {
+		return api.PullRequest{}, fmt.Errorf("update pr: %w", err)
+	}
+
+	ready := cmd.RowsAffected() > 0
+	if ready {
+		if err := assignReviewersTx(ctx, tx, prID, assignment); err != nil {
+			return api.PullRequest{}, err
 		}
 	}
 
@@ -286,6 +532,11 @@ func (r *Repo) CreatePullRequest(ctx context.Context, prID, prName, authorID str
 	if err != nil {
 		return api.PullRequest{}, err
 	}
+	if ready {
+		if err := publishTx(ctx, tx, api.PullRequestReadyForReview, pr); err != nil {
+			return api.PullRequest{}, err
+		}
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return api.PullRequest{}, fmt.Errorf("commit tx: %w", err)
@@ -293,17 +544,94 @@ func (r *Repo) CreatePullRequest(ctx context.Context, prID, prName, authorID str
 	return pr, nil
 }
 
+func (r *Repo) GetRotationCursor(ctx context.Context, teamName string) (string, error) {
+	var cursor string
+	err := r.pool.QueryRow(ctx,
+		`SELECT last_user_id
+		   FROM team_rotation_cursors
+		  WHERE team_name = $1`,
+		teamName,
+	).Scan(&cursor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get rotation cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+// assignReviewersTx inserts the assignment's reviewers into a pull request
+// that has none yet. A rotation cursor is locked for the rest of the transaction, so
+// concurrent assignments for the same team are serialized.
+func assignReviewersTx(ctx context.Context, tx pgx.Tx, prID string, assignment Assignment) error {
+	reviewerIDs := assignment.ReviewerIDs
+
+	if rot := assignment.Rotation; rot != nil {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO team_rotation_cursors (team_name)
+			 VALUES ($1)
+			 ON CONFLICT (team_name) DO NOTHING`,
+			rot.TeamName,
+		); err != nil {
+			return fmt.Errorf("init rotation cursor: %w", err)
+		}
+
+		var cursor string
+		if err := tx.QueryRow(ctx,
+			`SELECT last_user_id
+			   FROM team_rotation_cursors
+			  WHERE team_name = $1
+			    FOR UPDATE`,
+			rot.TeamName,
+		).Scan(&cursor); err != nil {
+			return fmt.Errorf("lock rotation cursor: %w", err)
+		}
+
+		rotated, next := rot.Next(cursor)
+		reviewerIDs = append(append([]string(nil), reviewerIDs...), rotated...)
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE team_rotation_cursors
+			    SET last_user_id = $2
+			  WHERE team_name = $1`,
+			rot.TeamName, next,
+		); err != nil {
+			return fmt.Errorf("advance rotation cursor: %w", err)
+		}
+	}
+
+	reviewerIDs = append(append([]string(nil), reviewerIDs...), assignment.FallbackIDs...)
+
+	for i, rid := range reviewerIDs {
+		slot := int16(i + 1)
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO pr_reviewers (pr_id, slot, reviewer_id, team_name)
+			 SELECT $1, $2, $3, team_name
+			   FROM users
+			  WHERE user_id = $3`,
+			prID, slot, rid,
+		); err != nil {
+			return fmt.Errorf("insert reviewer: %w", err)
+		}
+	}
+	return nil
+}
+
 func loadPullRequestTx(ctx context.Context, tx pgx.Tx, prID string) (api.PullRequest, error) {
 	var id, name, authorID, statusStr string
 	var createdAt time.Time
-	var mergedAt *time.Time
+	var mergedAt, closedAt *time.Time
+	var forceMerged, draft bool
+	var changedPaths, labels []string
 
 	err := tx.QueryRow(ctx,
-		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at,
+		        force_merged, is_draft, changed_paths, labels
 		   FROM pull_requests
 		  WHERE pull_request_id = $1`,
 		prID,
-	).Scan(&id, &name, &authorID, &statusStr, &createdAt, &mergedAt)
+	).Scan(&id, &name, &authorID, &statusStr, &createdAt, &mergedAt, &closedAt, &forceMerged, &draft, &changedPaths, &labels)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return api.PullRequest{}, ErrNotFound
 	}
@@ -312,7 +640,7 @@ func loadPullRequestTx(ctx context.Context, tx pgx.Tx, prID string) (api.PullReq
 	}
 
 	rows, err := tx.Query(ctx,
-		`SELECT reviewer_id
+		`SELECT reviewer_id, decision, decided_at, assigned_at, team_name
 		   FROM pr_reviewers
 		  WHERE pr_id = $1
 		  ORDER BY slot`,
@@ -324,12 +652,14 @@ func loadPullRequestTx(ctx context.Context, tx pgx.Tx, prID string) (api.PullReq
 	defer rows.Close()
 
 	var reviewers []string
+	details := []api.Reviewer{}
 	for rows.Next() {
-		var rid string
-		if err := rows.Scan(&rid); err != nil {
+		var rev api.Reviewer
+		if err := rows.Scan(&rev.UserId, &rev.Decision, &rev.DecidedAt, &rev.AssignedAt, &rev.TeamName); err != nil {
 			return api.PullRequest{}, fmt.Errorf("scan reviewer: %w", err)
 		}
-		reviewers = append(reviewers, rid)
+		reviewers = append(reviewers, rev.UserId)
+		details = append(details, rev)
 	}
 	if err := rows.Err(); err != nil {
 		return api.PullRequest{}, fmt.Errorf("rows err: %w", err)
@@ -343,9 +673,15 @@ func loadPullRequestTx(ctx context.Context, tx pgx.Tx, prID string) (api.PullReq
 		AuthorId:          authorID,
 		Status:            status,
 		AssignedReviewers: reviewers,
+		Reviewers:         &details,
 	}
 	pr.CreatedAt = &createdAt
 	pr.MergedAt = mergedAt
+	pr.ClosedAt = closedAt
+	pr.ForceMerged = &forceMerged
+	pr.Draft = &draft
+	pr.ChangedPaths = &changedPaths
+	pr.Labels = &labels
 
 	return pr, nil
 }
@@ -368,19 +704,21 @@ func (r *Repo) GetPullRequest(ctx context.Context, prID string) (api.PullRequest
 	return pr, nil
 }
 
-func (r *Repo) MarkPullRequestMerged(ctx context.Context, prID string) (api.PullRequest, error) {
+func (r *Repo) MarkPullRequestMerged(ctx context.Context, prID string, force bool) (api.PullRequest, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return api.PullRequest{}, fmt.Errorf("begin tx: %w", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, err = tx.Exec(ctx,
+	cmd, err := tx.Exec(ctx,
 		`UPDATE pull_requests
 		    SET status = 'MERGED',
-		        merged_at = COALESCE(merged_at, now())
-		  WHERE pull_request_id = $1`,
-		prID,
+		        merged_at = COALESCE(merged_at, now()),
+		        force_merged = $2
+		  WHERE pull_request_id = $1
+		    AND status = 'OPEN'`,
+		prID, force,
 	)
 	if err != nil {
 		return api.PullRequest{}, fmt.Errorf("update pr: %w", err)
@@ -390,6 +728,192 @@ func (r *Repo) MarkPullRequestMerged(ctx context.Context, prID string) (api.Pull
 	if err != nil {
 		return api.PullRequest{}, err
 	}
+	if cmd.RowsAffected() > 0 {
+		if err := publishTx(ctx, tx, api.PullRequestMerged, pr); err != nil {
+			return api.PullRequest{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.PullRequest{}, fmt.Errorf("commit: %w", err)
+	}
+	return pr, nil
+}
+
+func (r *Repo) MarkPullRequestClosed(ctx context.Context, prID string) (api.PullRequest, error) {
+	return r.updatePullRequestStatus(ctx, prID, api.PullRequestClosed,
+		`UPDATE pull_requests
+		    SET status = 'CLOSED',
+		        closed_at = now()
+		  WHERE pull_request_id = $1
+		    AND status = 'OPEN'`,
+	)
+}
+
+// ReopenPullRequest moves a closed pull request back to OPEN and hands the
+// slots of its inactive reviewers over in the same transaction. plan gets
+// those slots and the candidates that are active and available, and fills
+// in NewReviewerID for the slots it can reassign. Reopening a pull request
+// that is not closed changes nothing and skips plan.
+func (r *Repo) ReopenPullRequest(ctx context.Context, prID string, candidates []string, plan HandoffPlan) (api.PullRequest, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.PullRequest{}, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx,
+		`UPDATE pull_requests
+		    SET status = 'OPEN',
+		        closed_at = NULL
+		  WHERE pull_request_id = $1
+		    AND status = 'CLOSED'`,
+		prID,
+	)
+	if err != nil {
+		return api.PullRequest{}, nil, fmt.Errorf("update pr status: %w", err)
+	}
+
+	pr, err := loadPullRequestTx(ctx, tx, prID)
+	if err != nil {
+		return api.PullRequest{}, nil, err
+	}
+	if cmd.RowsAffected() == 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return api.PullRequest{}, nil, fmt.Errorf("commit: %w", err)
+		}
+		return pr, nil, nil
+	}
+	if err := publishTx(ctx, tx, api.PullRequestReopened, pr); err != nil {
+		return api.PullRequest{}, nil, err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT r.reviewer_id
+		   FROM pr_reviewers r
+		   JOIN users u ON u.user_id = r.reviewer_id
+		  WHERE r.pr_id = $1
+		    AND NOT u.is_active
+		  ORDER BY r.slot`,
+		prID,
+	)
+	if err != nil {
+		return api.PullRequest{}, nil, fmt.Errorf("select inactive reviewers: %w", err)
+	}
+	var inactive []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return api.PullRequest{}, nil, fmt.Errorf("scan inactive reviewer: %w", err)
+		}
+		inactive = append(inactive, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return api.PullRequest{}, nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	var slots []ReviewSlot
+	if len(inactive) > 0 {
+		if slots, err = openReviewSlotsTx(ctx, tx, inactive, prID); err != nil {
+			return api.PullRequest{}, nil, err
+		}
+		active, err := availableUsersTx(ctx, tx, "user_id = ANY($1)", candidates)
+		if err != nil {
+			return api.PullRequest{}, nil, err
+		}
+		plan(active, slots)
+
+		var oldIDs, newIDs []string
+		for _, slot := range slots {
+			if slot.NewReviewerID != "" {
+				oldIDs = append(oldIDs, slot.OldReviewerID)
+				newIDs = append(newIDs, slot.NewReviewerID)
+			}
+		}
+		if len(oldIDs) > 0 {
+			if pr, err = replaceReviewersTx(ctx, tx, prID, oldIDs, newIDs); err != nil {
+				return api.PullRequest{}, nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.PullRequest{}, nil, fmt.Errorf("commit: %w", err)
+	}
+	return pr, slots, nil
+}
+
+// replaceReviewersTx hands the slots of oldIDs on prID over to newIDs,
+// publishes the reassignments and returns the updated pull request.
+func replaceReviewersTx(ctx context.Context, tx pgx.Tx, prID string, oldIDs, newIDs []string) (api.PullRequest, error) {
+	prIDs := make([]string, len(oldIDs))
+	for i := range prIDs {
+		prIDs[i] = prID
+	}
+
+	cmd, err := tx.Exec(ctx,
+		`UPDATE pr_reviewers r
+		    SET reviewer_id = h.new_id,
+		        team_name = u.team_name,
+		        assigned_at = now(),
+		        decision = NULL,
+		        decided_at = NULL
+		   FROM unnest($2::text[], $3::text[]) AS h(old_id, new_id)
+		   JOIN users u ON u.user_id = h.new_id
+		  WHERE r.pr_id = $1
+		    AND r.reviewer_id = h.old_id`,
+		prID, oldIDs, newIDs,
+	)
+	if err != nil {
+		return api.PullRequest{}, fmt.Errorf("replace reviewers: %w", err)
+	}
+	if cmd.RowsAffected() != int64(len(oldIDs)) {
+		return api.PullRequest{}, ErrReviewerNotFound
+	}
+	if err := publishReassignmentsTx(ctx, tx, prIDs, oldIDs, newIDs); err != nil {
+		return api.PullRequest{}, err
+	}
+	return loadPullRequestTx(ctx, tx, prID)
+}
+
+// MarkPullRequestDraft puts an open pull request back into draft state.
+// Its reviewers stay assigned. No event is published, since webhook
+// subscribers have no draft event to receive.
+func (r *Repo) MarkPullRequestDraft(ctx context.Context, prID string) (api.PullRequest, error) {
+	return r.updatePullRequestStatus(ctx, prID, "",
+		`UPDATE pull_requests
+		    SET is_draft = true
+		  WHERE pull_request_id = $1
+		    AND NOT is_draft
+		    AND status = 'OPEN'`,
+	)
+}
+
+// updatePullRequestStatus runs query and publishes event, if one is given,
+// when it changed the pull request.
+func (r *Repo) updatePullRequestStatus(ctx context.Context, prID string, event api.WebhookEventType, query string) (api.PullRequest, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.PullRequest{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx, query, prID)
+	if err != nil {
+		return api.PullRequest{}, fmt.Errorf("update pr status: %w", err)
+	}
+
+	pr, err := loadPullRequestTx(ctx, tx, prID)
+	if err != nil {
+		return api.PullRequest{}, err
+	}
+	if event != "" && cmd.RowsAffected() > 0 {
+		if err := publishTx(ctx, tx, event, pr); err != nil {
+			return api.PullRequest{}, err
+		}
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return api.PullRequest{}, fmt.Errorf("commit: %w", err)
@@ -398,9 +922,19 @@ func (r *Repo) MarkPullRequestMerged(ctx context.Context, prID string) (api.Pull
 }
 
 func (r *Repo) ReplaceReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
-	cmd, err := r.pool.Exec(ctx,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx,
 		`UPDATE pr_reviewers
-		    SET reviewer_id = $3
+		    SET reviewer_id = $3,
+		        team_name = (SELECT team_name FROM users WHERE user_id = $3),
+		        assigned_at = now(),
+		        decision = NULL,
+		        decided_at = NULL
 		  WHERE pr_id = $1
 		    AND reviewer_id = $2`,
 		prID, oldUserID, newUserID,
@@ -411,20 +945,81 @@ func (r *Repo) ReplaceReviewer(ctx context.Context, prID, oldUserID, newUserID s
 	if cmd.RowsAffected() == 0 {
 		return ErrReviewerNotFound
 	}
+	if err := publishReassignmentsTx(ctx, tx, []string{prID}, []string{oldUserID}, []string{newUserID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
 	return nil
 }
 
-func (r *Repo) ListUserReviewPRs(ctx context.Context, userID string) ([]api.PullRequestShort, error) {
+func (r *Repo) SetReviewDecision(ctx context.Context, prID, reviewerID string, decision api.ReviewDecision) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx,
+		`UPDATE pr_reviewers
+		    SET decision = $3,
+		        decided_at = now()
+		  WHERE pr_id = $1
+		    AND reviewer_id = $2`,
+		prID, reviewerID, string(decision),
+	)
+	if err != nil {
+		return fmt.Errorf("update decision: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrReviewerNotFound
+	}
+
+	pr, err := loadPullRequestTx(ctx, tx, prID)
+	if err != nil {
+		return err
+	}
+	if err := publishTx(ctx, tx, api.PullRequestReviewSubmitted, pr, func(ev *api.WebhookEvent) {
+		if pr.Reviewers == nil {
+			return
+		}
+		for _, rev := range *pr.Reviewers {
+			if rev.UserId == reviewerID {
+				ev.Review = &rev
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// ListUserReviewPRs returns open and merged pull requests the user
+// reviews; closed ones are left out. With pendingOnly set, only OPEN ones
+// still waiting for the user's approval or change request are returned.
+func (r *Repo) ListUserReviewPRs(ctx context.Context, userID string, pendingOnly bool) ([]api.PullRequestShort, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT pr.pull_request_id,
 		        pr.pull_request_name,
 		        pr.author_id,
-		        pr.status
+		        pr.status,
+		        r.decision
 		   FROM pull_requests pr
 		   JOIN pr_reviewers r ON pr.pull_request_id = r.pr_id
 		  WHERE r.reviewer_id = $1
+		    AND pr.status <> 'CLOSED'
+		    AND (NOT $2
+		         OR (pr.status = 'OPEN'
+		             AND r.decision IS DISTINCT FROM 'APPROVED'
+		             AND r.decision IS DISTINCT FROM 'CHANGES_REQUESTED'))
 		  ORDER BY pr.created_at`,
-		userID,
+		userID, pendingOnly,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("select prs: %w", err)
@@ -434,7 +1029,8 @@ func (r *Repo) ListUserReviewPRs(ctx context.Context, userID string) ([]api.Pull
 	var res []api.PullRequestShort
 	for rows.Next() {
 		var id, name, authorID, statusStr string
-		if err := rows.Scan(&id, &name, &authorID, &statusStr); err != nil {
+		var decision *api.ReviewDecision
+		if err := rows.Scan(&id, &name, &authorID, &statusStr, &decision); err != nil {
 			return nil, fmt.Errorf("scan pr: %w", err)
 		}
 		res = append(res, api.PullRequestShort{
@@ -442,6 +1038,7 @@ func (r *Repo) ListUserReviewPRs(ctx context.Context, userID string) ([]api.Pull
 			PullRequestName: name,
 			AuthorId:        authorID,
 			Status:          api.PullRequestShortStatus(statusStr),
+			Decision:        decision,
 		})
 	}
 	if err := rows.Err(); err != nil {
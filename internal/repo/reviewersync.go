@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pr-reviewer/internal/api"
+)
+
+// ReviewerSyncJob is a reviewer change on a pull request waiting to be
+// pushed to the Git host the pull request mirrors.
+type ReviewerSyncJob struct {
+	ID            int64
+	PullRequestID string
+	Provider      api.GitProvider
+	AddIDs        []string
+	RemoveIDs     []string
+	// Attempts counts deliveries including the current one.
+	Attempts int
+}
+
+// ClaimReviewerSyncJobs takes up to limit due jobs, at most one per pull
+// request so that changes reach the host in order, and hides them from
+// other workers for lease. A job whose worker dies becomes due again once
+// the lease runs out.
+func (r *Repo) ClaimReviewerSyncJobs(ctx context.Context, limit int, lease time.Duration) ([]ReviewerSyncJob, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE reviewer_sync_queue q
+		    SET attempts = q.attempts + 1,
+		        next_attempt_at = now() + $2 * interval '1 millisecond'
+		   FROM (SELECT j.id
+		           FROM reviewer_sync_queue j
+		          WHERE j.failed_at IS NULL
+		            AND j.next_attempt_at <= now()
+		            AND NOT EXISTS (
+		              SELECT 1
+		                FROM reviewer_sync_queue e
+		               WHERE e.pull_request_id = j.pull_request_id
+		                 AND e.id < j.id
+		                 AND e.failed_at IS NULL)
+		          ORDER BY j.id
+		          LIMIT $1
+		            FOR UPDATE SKIP LOCKED) due
+		  WHERE q.id = due.id
+		  RETURNING q.id, q.pull_request_id, q.provider, q.add_ids, q.remove_ids, q.attempts`,
+		limit, lease.Milliseconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim reviewer sync jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []ReviewerSyncJob
+	for rows.Next() {
+		var j ReviewerSyncJob
+		if err := rows.Scan(&j.ID, &j.PullRequestID, &j.Provider, &j.AddIDs, &j.RemoveIDs, &j.Attempts); err != nil {
+			return nil, fmt.Errorf("scan reviewer sync job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *Repo) CompleteReviewerSyncJob(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx,
+		`DELETE FROM reviewer_sync_queue WHERE id = $1`,
+		id,
+	); err != nil {
+		return fmt.Errorf("complete reviewer sync job: %w", err)
+	}
+	return nil
+}
+
+func (r *Repo) RetryReviewerSyncJob(ctx context.Context, id int64, delay time.Duration, lastErr string) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE reviewer_sync_queue
+		    SET next_attempt_at = now() + $2 * interval '1 millisecond',
+		        last_error = $3
+		  WHERE id = $1`,
+		id, delay.Milliseconds(), lastErr,
+	); err != nil {
+		return fmt.Errorf("retry reviewer sync job: %w", err)
+	}
+	return nil
+}
+
+// FailReviewerSyncJob gives up on the job. Failed jobs stay in the queue
+// for inspection and no longer hold back later changes of the pull
+// request.
+func (r *Repo) FailReviewerSyncJob(ctx context.Context, id int64, lastErr string) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE reviewer_sync_queue
+		    SET failed_at = now(),
+		        last_error = $2
+		  WHERE id = $1`,
+		id, lastErr,
+	); err != nil {
+		return fmt.Errorf("fail reviewer sync job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+func (r *Repo) GetTeamSettings(ctx context.Context, teamName string) (api.TeamSettings, error) {
+	var strategy string
+	var weights map[string]int
+	var reviewersCount, requiredApprovals, maxOpenReviews, diversityWindow *int
+	var blockOnChanges, requireSenior, juniorShadow *bool
+	var fallbackTeams []string
+
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(s.reviewer_strategy, ''),
+		        COALESCE(s.reviewer_weights, '{}'::jsonb),
+		        s.reviewers_count,
+		        s.required_approvals,
+		        s.block_on_changes_requested,
+		        s.max_open_reviews,
+		        COALESCE(s.fallback_teams, '{}'),
+		        s.require_senior,
+		        s.junior_shadow,
+		        s.diversity_window
+		   FROM teams t
+		   LEFT JOIN team_settings s ON s.team_name = t.team_name
+		  WHERE t.team_name = $1`,
+		teamName,
+	).Scan(&strategy, &weights, &reviewersCount, &requiredApprovals, &blockOnChanges, &maxOpenReviews, &fallbackTeams,
+		&requireSenior, &juniorShadow, &diversityWindow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.TeamSettings{}, ErrNotFound
+	}
+	if err != nil {
+		return api.TeamSettings{}, fmt.Errorf("get team settings: %w", err)
+	}
+
+	return api.TeamSettings{
+		TeamName:                teamName,
+		ReviewerStrategy:        api.ReviewerStrategy(strategy),
+		ReviewerWeights:         &weights,
+		ReviewersCount:          reviewersCount,
+		RequiredApprovals:       requiredApprovals,
+		BlockOnChangesRequested: blockOnChanges,
+		MaxOpenReviews:          maxOpenReviews,
+		FallbackTeams:           &fallbackTeams,
+		RequireSenior:           requireSenior,
+		JuniorShadow:            juniorShadow,
+		DiversityWindow:         diversityWindow,
+	}, nil
+}
+
+// UpsertTeamSettings stores settings as given; the caller is expected to
+// fill in stored values or defaults for omitted fields.
+func (r *Repo) UpsertTeamSettings(ctx context.Context, settings api.TeamSettings) (api.TeamSettings, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.TeamSettings{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`,
+		settings.TeamName,
+	).Scan(&exists); err != nil {
+		return api.TeamSettings{}, fmt.Errorf("check team exists: %w", err)
+	}
+	if !exists {
+		return api.TeamSettings{}, ErrNotFound
+	}
+
+	var known int
+	if err := tx.QueryRow(ctx,
+		`SELECT count(*) FROM teams WHERE team_name = ANY($1)`,
+		*settings.FallbackTeams,
+	).Scan(&known); err != nil {
+		return api.TeamSettings{}, fmt.Errorf("check fallback teams: %w", err)
+	}
+	if known != len(*settings.FallbackTeams) {
+		return api.TeamSettings{}, ErrFallbackNotFound
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO team_settings (
+		   team_name, reviewer_strategy, reviewer_weights, reviewers_count,
+		   required_approvals, block_on_changes_requested, max_open_reviews, fallback_teams,
+		   require_senior, junior_shadow, diversity_window)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 ON CONFLICT (team_name) DO UPDATE
+		   SET reviewer_strategy = EXCLUDED.reviewer_strategy,
+		       reviewer_weights = EXCLUDED.reviewer_weights,
+		       reviewers_count = EXCLUDED.reviewers_count,
+		       required_approvals = EXCLUDED.required_approvals,
+		       block_on_changes_requested = EXCLUDED.block_on_changes_requested,
+		       max_open_reviews = EXCLUDED.max_open_reviews,
+		       fallback_teams = EXCLUDED.fallback_teams,
+		       require_senior = EXCLUDED.require_senior,
+		       junior_shadow = EXCLUDED.junior_shadow,
+		       diversity_window = EXCLUDED.diversity_window`,
+		settings.TeamName, string(settings.ReviewerStrategy), *settings.ReviewerWeights, *settings.ReviewersCount,
+		*settings.RequiredApprovals, *settings.BlockOnChangesRequested, settings.MaxOpenReviews, *settings.FallbackTeams,
+		*settings.RequireSenior, *settings.JuniorShadow, *settings.DiversityWindow,
+	); err != nil {
+		return api.TeamSettings{}, fmt.Errorf("upsert team settings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.TeamSettings{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,279 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// NewWebhookSubscription is a subscriber to register. Empty Events
+// subscribes it to every event.
+type NewWebhookSubscription struct {
+	URL    string
+	Secret string
+	Events []api.WebhookEventType
+}
+
+// WebhookDeliveryJob is an event waiting to be sent to a subscriber.
+type WebhookDeliveryJob struct {
+	ID             int64
+	SubscriptionID int64
+	URL            string
+	Secret         string
+	Event          api.WebhookEventType
+	Payload        []byte
+	// Attempts counts deliveries including the current one.
+	Attempts int
+}
+
+const webhookDeliveriesLimit = 100
+
+func (r *Repo) CreateWebhookSubscription(ctx context.Context, sub NewWebhookSubscription) (api.WebhookSubscription, error) {
+	var created api.WebhookSubscription
+	var events []string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, events)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, url, events, created_at`,
+		sub.URL, sub.Secret, eventNames(sub.Events),
+	).Scan(&created.SubscriptionId, &created.Url, &events, &created.CreatedAt)
+	if err != nil {
+		return api.WebhookSubscription{}, fmt.Errorf("insert webhook subscription: %w", err)
+	}
+	created.Events = eventTypes(events)
+	return created, nil
+}
+
+func (r *Repo) ListWebhookSubscriptions(ctx context.Context) ([]api.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, url, events, created_at
+		   FROM webhook_subscriptions
+		  ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []api.WebhookSubscription{}
+	for rows.Next() {
+		var sub api.WebhookSubscription
+		var events []string
+		if err := rows.Scan(&sub.SubscriptionId, &sub.Url, &events, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan webhook subscription: %w", err)
+		}
+		sub.Events = eventTypes(events)
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteWebhookSubscription removes the subscription together with its
+// deliveries.
+func (r *Repo) DeleteWebhookSubscription(ctx context.Context, id int64) (api.WebhookSubscription, error) {
+	var deleted api.WebhookSubscription
+	var events []string
+	err := r.pool.QueryRow(ctx,
+		`DELETE FROM webhook_subscriptions
+		  WHERE id = $1
+		  RETURNING id, url, events, created_at`,
+		id,
+	).Scan(&deleted.SubscriptionId, &deleted.Url, &events, &deleted.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.WebhookSubscription{}, ErrNotFound
+	}
+	if err != nil {
+		return api.WebhookSubscription{}, fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	deleted.Events = eventTypes(events)
+	return deleted, nil
+}
+
+// ClaimWebhookDeliveries takes up to limit due deliveries and hides them
+// from other workers for lease. A delivery whose worker dies becomes due
+// again once the lease runs out.
+func (r *Repo) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDeliveryJob, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH claimed AS (
+		   UPDATE webhook_deliveries d
+		      SET attempts = d.attempts + 1,
+		          next_attempt_at = now() + $2 * interval '1 millisecond'
+		     FROM (SELECT id
+		             FROM webhook_deliveries
+		            WHERE status = 'pending'
+		              AND next_attempt_at <= now()
+		            ORDER BY id
+		            LIMIT $1
+		              FOR UPDATE SKIP LOCKED) due
+		    WHERE d.id = due.id
+		    RETURNING d.id, d.subscription_id, d.event, d.payload, d.attempts
+		 )
+		 SELECT c.id, c.subscription_id, s.url, s.secret, c.event, c.payload, c.attempts
+		   FROM claimed c
+		   JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		  ORDER BY c.id`,
+		limit, lease.Milliseconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []WebhookDeliveryJob
+	for rows.Next() {
+		var j WebhookDeliveryJob
+		if err := rows.Scan(&j.ID, &j.SubscriptionID, &j.URL, &j.Secret, &j.Event, &j.Payload, &j.Attempts); err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *Repo) CompleteWebhookDelivery(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE webhook_deliveries
+		    SET status = 'delivered',
+		        delivered_at = now(),
+		        last_error = NULL
+		  WHERE id = $1`,
+		id,
+	); err != nil {
+		return fmt.Errorf("complete webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *Repo) RetryWebhookDelivery(ctx context.Context, id int64, delay time.Duration, lastErr string) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE webhook_deliveries
+		    SET next_attempt_at = now() + $2 * interval '1 millisecond',
+		        last_error = $3
+		  WHERE id = $1`,
+		id, delay.Milliseconds(), lastErr,
+	); err != nil {
+		return fmt.Errorf("retry webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// DeadLetterWebhookDelivery gives up on the delivery until it is
+// redelivered by hand.
+func (r *Repo) DeadLetterWebhookDelivery(ctx context.Context, id int64, lastErr string) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE webhook_deliveries
+		    SET status = 'dead',
+		        last_error = $2
+		  WHERE id = $1`,
+		id, lastErr,
+	); err != nil {
+		return fmt.Errorf("dead-letter webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the latest deliveries of the subscription,
+// newest first, optionally only those with status.
+func (r *Repo) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status api.WebhookDeliveryStatus) ([]api.WebhookDelivery, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1)`,
+		subscriptionID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check webhook subscription: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+webhookDeliveryColumns+`
+		   FROM webhook_deliveries
+		  WHERE subscription_id = $1
+		    AND ($2 = '' OR status = $2)
+		  ORDER BY id DESC
+		  LIMIT $3`,
+		subscriptionID, string(status), webhookDeliveriesLimit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []api.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhookDelivery puts the delivery back in the queue with its
+// attempts reset.
+func (r *Repo) RedeliverWebhookDelivery(ctx context.Context, id int64) (api.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.pool.QueryRow(ctx,
+		`UPDATE webhook_deliveries
+		    SET status = 'pending',
+		        attempts = 0,
+		        next_attempt_at = now(),
+		        delivered_at = NULL
+		  WHERE id = $1
+		  RETURNING `+webhookDeliveryColumns,
+		id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.WebhookDelivery{}, ErrNotFound
+	}
+	return d, err
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event, status, attempts, next_attempt_at, last_error, created_at, delivered_at`
+
+func scanWebhookDelivery(row pgx.Row) (api.WebhookDelivery, error) {
+	var d api.WebhookDelivery
+	var next time.Time
+	if err := row.Scan(&d.DeliveryId, &d.SubscriptionId, &d.Event, &d.Status, &d.Attempts,
+		&next, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return api.WebhookDelivery{}, err
+		}
+		return api.WebhookDelivery{}, fmt.Errorf("scan webhook delivery: %w", err)
+	}
+	if d.Status == api.Pending {
+		d.NextAttemptAt = &next
+	}
+	return d, nil
+}
+
+func eventNames(events []api.WebhookEventType) []string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, string(e))
+	}
+	return names
+}
+
+func eventTypes(names []string) []api.WebhookEventType {
+	events := make([]api.WebhookEventType, 0, len(names))
+	for _, n := range names {
+		events = append(events, api.WebhookEventType(n))
+	}
+	return events
+}
@@ -0,0 +1,400 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+
+	"pr-reviewer/internal/api"
+)
+
+// AddTeamMembers creates new users in the team and updates the ones that
+// already belong to it. Users without a team are taken in; users of any
+// other team fail the whole call with ErrUserInOtherTeam. Active members
+// the call deactivates hand their open reviews over through plan, which
+// may be nil when no member is inactive.
+func (r *Repo) AddTeamMembers(ctx context.Context, teamName string, members []api.TeamMember, plan HandoffPlan) (api.Team, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.Team{}, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockTeamTx(ctx, tx, teamName); err != nil {
+		return api.Team{}, nil, err
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserId)
+	}
+
+	var foreign bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(
+		   SELECT 1
+		     FROM users
+		    WHERE user_id = ANY($1)
+		      AND team_name <> $2)`,
+		ids, teamName,
+	).Scan(&foreign); err != nil {
+		return api.Team{}, nil, fmt.Errorf("check member teams: %w", err)
+	}
+	if foreign {
+		return api.Team{}, nil, ErrUserInOtherTeam
+	}
+
+	var inactive []string
+	for _, m := range members {
+		if !m.IsActive {
+			inactive = append(inactive, m.UserId)
+		}
+	}
+	var deactivated []string
+	if len(inactive) > 0 {
+		rows, err := tx.Query(ctx,
+			`SELECT user_id
+			   FROM users
+			  WHERE team_name = $1
+			    AND user_id = ANY($2)
+			    AND is_active
+			  ORDER BY user_id`,
+			teamName, inactive,
+		)
+		if err != nil {
+			return api.Team{}, nil, fmt.Errorf("select active members: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return api.Team{}, nil, fmt.Errorf("scan active member: %w", err)
+			}
+			deactivated = append(deactivated, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return api.Team{}, nil, fmt.Errorf("rows err: %w", err)
+		}
+	}
+
+	for _, m := range members {
+		if err := upsertMemberTx(ctx, tx, teamName, m); err != nil {
+			return api.Team{}, nil, err
+		}
+	}
+
+	var slots []ReviewSlot
+	if len(deactivated) > 0 {
+		if slots, err = handOffReviewsTx(ctx, tx, teamName, deactivated, plan); err != nil {
+			return api.Team{}, nil, err
+		}
+	}
+
+	team, err := loadTeamTx(ctx, tx, teamName)
+	if err != nil {
+		return api.Team{}, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.Team{}, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return team, slots, nil
+}
+
+// RemoveTeamMembers detaches the users from the team and deactivates them,
+// handing their open reviews over to the remaining members. It refuses
+// with ErrTeamHasOpenPRs while any of them authors an open pull request,
+// whose merge policy depends on the author's team.
+func (r *Repo) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, plan HandoffPlan) (api.Team, []api.User, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.Team{}, nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockTeamTx(ctx, tx, teamName); err != nil {
+		return api.Team{}, nil, nil, err
+	}
+
+	var busy bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(
+		   SELECT 1
+		     FROM pull_requests pr
+		     JOIN users u ON u.user_id = pr.author_id
+		    WHERE u.team_name = $1
+		      AND u.user_id = ANY($2)
+		      AND pr.status = 'OPEN')`,
+		teamName, userIDs,
+	).Scan(&busy); err != nil {
+		return api.Team{}, nil, nil, fmt.Errorf("check open pull requests: %w", err)
+	}
+	if busy {
+		return api.Team{}, nil, nil, ErrTeamHasOpenPRs
+	}
+
+	removed, err := detachUsersTx(ctx, tx, teamName, userIDs)
+	if err != nil {
+		return api.Team{}, nil, nil, err
+	}
+	if len(removed) != len(userIDs) {
+		return api.Team{}, nil, nil, ErrNotTeamMember
+	}
+
+	slots, err := handOffReviewsTx(ctx, tx, teamName, userIDs, plan)
+	if err != nil {
+		return api.Team{}, nil, nil, err
+	}
+
+	team, err := loadTeamTx(ctx, tx, teamName)
+	if err != nil {
+		return api.Team{}, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.Team{}, nil, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return team, removed, slots, nil
+}
+
+// DeleteTeam detaches and deactivates every member and drops the team with
+// its settings, removing it from other teams' fallback lists. It refuses
+// with ErrTeamHasOpenPRs while any member authors or reviews an open pull
+// request.
+func (r *Repo) DeleteTeam(ctx context.Context, teamName string) ([]api.User, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockTeamTx(ctx, tx, teamName); err != nil {
+		return nil, err
+	}
+
+	var busy bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(
+		   SELECT 1
+		     FROM pull_requests pr
+		     JOIN users u ON u.user_id = pr.author_id
+		    WHERE u.team_name = $1
+		      AND pr.status = 'OPEN'
+		   UNION ALL
+		   SELECT 1
+		     FROM pr_reviewers r
+		     JOIN pull_requests pr ON pr.pull_request_id = r.pr_id
+		     JOIN users u ON u.user_id = r.reviewer_id
+		    WHERE u.team_name = $1
+		      AND pr.status = 'OPEN')`,
+		teamName,
+	).Scan(&busy); err != nil {
+		return nil, fmt.Errorf("check open pull requests: %w", err)
+	}
+	if busy {
+		return nil, ErrTeamHasOpenPRs
+	}
+
+	detached, err := detachUsersTx(ctx, tx, teamName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM teams WHERE team_name = $1`,
+		teamName,
+	); err != nil {
+		return nil, fmt.Errorf("delete team: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE team_settings
+		    SET fallback_teams = array_remove(fallback_teams, $1)
+		  WHERE $1 = ANY(fallback_teams)`,
+		teamName,
+	); err != nil {
+		return nil, fmt.Errorf("remove fallback team: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return detached, nil
+}
+
+// MoveUserToTeam moves the user to another team and records the move. With
+// a non-nil plan the user's open reviews are handed over to the remaining
+// active members of the old team in the same transaction. Moving a user to
+// the team they are already in changes nothing.
+func (r *Repo) MoveUserToTeam(ctx context.Context, userID, teamName string, plan HandoffPlan) (api.User, string, []ReviewSlot, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return api.User{}, "", nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var fromTeam string
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1`,
+		userID,
+	).Scan(&fromTeam)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.User{}, "", nil, ErrUserNotFound
+	}
+	if err != nil {
+		return api.User{}, "", nil, fmt.Errorf("get user team: %w", err)
+	}
+
+	// Lock both teams in name order so concurrent moves can't deadlock.
+	teams := []string{teamName}
+	if fromTeam != "" && fromTeam != teamName {
+		teams = append(teams, fromTeam)
+		sort.Strings(teams)
+	}
+	for _, name := range teams {
+		if err := lockTeamTx(ctx, tx, name); err != nil {
+			return api.User{}, "", nil, err
+		}
+	}
+
+	user, err := scanUser(tx.QueryRow(ctx,
+		`UPDATE users
+		    SET team_name = $2
+		  WHERE user_id = $1
+		  RETURNING `+userColumns,
+		userID, teamName,
+	))
+	if err != nil {
+		return api.User{}, "", nil, fmt.Errorf("update user: %w", err)
+	}
+	if fromTeam == teamName {
+		return user, fromTeam, nil, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO user_team_history (user_id, from_team, to_team)
+		 VALUES ($1, NULLIF($2, ''), $3)`,
+		userID, fromTeam, teamName,
+	); err != nil {
+		return api.User{}, "", nil, fmt.Errorf("record team move: %w", err)
+	}
+
+	var slots []ReviewSlot
+	if plan != nil && fromTeam != "" {
+		slots, err = handOffReviewsTx(ctx, tx, fromTeam, []string{userID}, plan)
+		if err != nil {
+			return api.User{}, "", nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return api.User{}, "", nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return user, fromTeam, slots, nil
+}
+
+func (r *Repo) ListTeamMoves(ctx context.Context, userID string) ([]api.TeamMove, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`,
+		userID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check user: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT user_id, from_team, to_team, moved_at
+		   FROM user_team_history
+		  WHERE user_id = $1
+		  ORDER BY id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select team moves: %w", err)
+	}
+	defer rows.Close()
+
+	moves := []api.TeamMove{}
+	for rows.Next() {
+		var m api.TeamMove
+		if err := rows.Scan(&m.UserId, &m.FromTeam, &m.ToTeam, &m.MovedAt); err != nil {
+			return nil, fmt.Errorf("scan team move: %w", err)
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return moves, nil
+}
+
+// upsertMemberTx creates m in the team or updates the stored user, recording
+// the move in history when an existing user changes teams, teamless users
+// included. Capacity, tags and level that m leaves out keep their stored
+// values, or take the defaults for a new user.
+func upsertMemberTx(ctx context.Context, tx pgx.Tx, teamName string, m api.TeamMember) error {
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO user_team_history (user_id, from_team, to_team)
+		 SELECT user_id, team_name, $2
+		   FROM users
+		  WHERE user_id = $1
+		    AND team_name IS DISTINCT FROM $2`,
+		m.UserId, teamName,
+	); err != nil {
+		return fmt.Errorf("record team move of %s: %w", m.UserId, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO users (user_id, username, team_name, is_active, max_open_reviews, tags, level)
+		 VALUES ($1, $2, $3, $4, $5, COALESCE($6, '{}'::text[]), COALESCE($7, 'mid'))
+		 ON CONFLICT (user_id) DO UPDATE
+		   SET username = EXCLUDED.username,
+		       team_name = EXCLUDED.team_name,
+		       is_active = EXCLUDED.is_active,
+		       max_open_reviews = COALESCE($5, users.max_open_reviews),
+		       tags = COALESCE($6, users.tags),
+		       level = COALESCE($7, users.level)`,
+		m.UserId, m.Username, teamName, m.IsActive, m.MaxOpenReviews, m.Tags, m.Level,
+	); err != nil {
+		return fmt.Errorf("upsert user %s: %w", m.UserId, err)
+	}
+	return nil
+}
+
+// detachUsersTx clears the team of the given members, or of every member
+// when userIDs is nil, and deactivates them.
+func detachUsersTx(ctx context.Context, tx pgx.Tx, teamName string, userIDs []string) ([]api.User, error) {
+	rows, err := tx.Query(ctx,
+		`UPDATE users
+		    SET team_name = NULL,
+		        is_active = false
+		  WHERE team_name = $1
+		    AND ($2::text[] IS NULL OR user_id = ANY($2))
+		  RETURNING `+userColumns,
+		teamName, userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("detach users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []api.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// handoffLeadTime is how long before a window starts ReassignReviews hands
+// the user's open reviews over, so that someone leaving tomorrow can clear
+// their queue today.
+const handoffLeadTime = 24 * time.Hour
+
+// handoffBatch is how many due handoffs one HandOffDueWindows call takes.
+const handoffBatch = 50
+
+// AddUnavailability records a window in which the user is not picked as a
+// reviewer. With ReassignReviews their open reviews are handed over
+// handoffLeadTime before the window starts: right away, with the returned
+// report describing the outcome, if that moment has passed, or else later
+// by HandOffDueWindows, with the window's HandoffAt saying when. Windows
+// that have already ended are refused.
+func (s *Service) AddUnavailability(ctx context.Context, req api.PostUsersAvailabilityAddJSONBody) (api.UnavailabilityWindow, *api.ReassignmentReport, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return api.UnavailabilityWindow{}, nil, NewError(api.BADREQUEST, "ends_at must be after starts_at")
+	}
+	now := time.Now()
+	if !req.EndsAt.After(now) {
+		return api.UnavailabilityWindow{}, nil, NewError(api.BADREQUEST, "ends_at must be in the future")
+	}
+	window := api.UnavailabilityWindow{
+		UserId:   req.UserId,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+	handoffAt := req.StartsAt.Add(-handoffLeadTime)
+	reassign := false
+	if req.ReassignReviews != nil && *req.ReassignReviews {
+		if handoffAt.After(now) {
+			window.HandoffAt = &handoffAt
+		} else {
+			reassign = true
+		}
+	}
+
+	var plan repo.HandoffPlan
+	if reassign {
+		user, err := s.repo.GetUser(ctx, req.UserId)
+		if err != nil {
+			if err == repo.ErrNotFound {
+				return api.UnavailabilityWindow{}, nil, NewError(api.NOTFOUND, "user not found")
+			}
+			return api.UnavailabilityWindow{}, nil, err
+		}
+		if user.TeamName != "" {
+			if plan, err = s.handoffPlan(ctx, user.TeamName); err != nil {
+				return api.UnavailabilityWindow{}, nil, err
+			}
+		}
+	}
+
+	window, slots, err := s.repo.AddUnavailability(ctx, window, plan)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.UnavailabilityWindow{}, nil, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.UnavailabilityWindow{}, nil, err
+	}
+
+	if !reassign {
+		return window, nil, nil
+	}
+	report := handoffReport(slots)
+	return window, &report, nil
+}
+
+// RunScheduledHandoffs hands over the reviews of users whose
+// unavailability is about to start every interval until ctx is done.
+func (s *Service) RunScheduledHandoffs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.HandOffDueWindows(ctx)
+			if err != nil {
+				log.Printf("scheduled handoff: %v", err)
+			}
+			if err != nil || n < handoffBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandOffDueWindows runs one batch of scheduled handoffs whose time has
+// come and returns how many it completed. A handoff whose user changed
+// teams meanwhile is left for the next call.
+func (s *Service) HandOffDueWindows(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDueHandoffs(ctx, handoffBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, d := range due {
+		var plan repo.HandoffPlan
+		if d.TeamName != "" {
+			if plan, err = s.handoffPlan(ctx, d.TeamName); err != nil {
+				return done, err
+			}
+		}
+		slots, err := s.repo.HandOffDueWindow(ctx, d.WindowID, d.TeamName, plan)
+		if err == repo.ErrNotTeamMember || err == repo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return done, err
+		}
+		done++
+
+		report := handoffReport(slots)
+		if len(report.Unassigned) > 0 {
+			log.Printf("scheduled handoff for %s: %d of %d reviews have no replacement",
+				d.UserID, len(report.Unassigned), len(slots))
+		}
+	}
+	return done, nil
+}
+
+func (s *Service) ListUnavailability(ctx context.Context, userID string) ([]api.UnavailabilityWindow, error) {
+	windows, err := s.repo.ListUnavailability(ctx, userID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return nil, NewError(api.NOTFOUND, "user not found")
+		}
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (s *Service) DeleteUnavailability(ctx context.Context, id int64) (api.UnavailabilityWindow, error) {
+	window, err := s.repo.DeleteUnavailability(ctx, id)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.UnavailabilityWindow{}, NewError(api.NOTFOUND, "unavailability window not found")
+		}
+		return api.UnavailabilityWindow{}, err
+	}
+	return window, nil
+}
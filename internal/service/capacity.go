@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// capacityLimit returns how many open reviews u may hold at once: their own
+// max_open_reviews, or the team default. ok is false when there is no limit.
+func capacityLimit(u api.User, settings api.TeamSettings) (limit int, ok bool) {
+	if u.MaxOpenReviews != nil {
+		return *u.MaxOpenReviews, true
+	}
+	if settings.MaxOpenReviews != nil {
+		return *settings.MaxOpenReviews, true
+	}
+	return 0, false
+}
+
+// filterByCapacity returns the ids of users still below their open review
+// limit. Open reviews are only counted when some user has a limit.
+func (s *Service) filterByCapacity(ctx context.Context, settings api.TeamSettings, users []api.User) ([]string, error) {
+	limits := make(map[string]int, len(users))
+	var limited []string
+	for _, u := range users {
+		if limit, ok := capacityLimit(u, settings); ok {
+			limits[u.UserId] = limit
+			limited = append(limited, u.UserId)
+		}
+	}
+
+	var open map[string]int
+	if len(limited) > 0 {
+		var err error
+		if open, err = s.repo.CountOpenReviews(ctx, limited); err != nil {
+			return nil, err
+		}
+	}
+
+	eligible := make([]string, 0, len(users))
+	for _, u := range users {
+		if limit, ok := limits[u.UserId]; ok && open[u.UserId] >= limit {
+			continue
+		}
+		eligible = append(eligible, u.UserId)
+	}
+	return eligible, nil
+}
+
+// eligibleReviewers is filterByCapacity, or just the users' ids when
+// capacity is ignored.
+func (s *Service) eligibleReviewers(ctx context.Context, settings api.TeamSettings, users []api.User, ignoreCapacity bool) ([]string, error) {
+	if !ignoreCapacity {
+		return s.filterByCapacity(ctx, settings, users)
+	}
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserId)
+	}
+	return ids, nil
+}
+
+func validateMaxOpenReviews(limit *int) error {
+	if limit != nil && *limit < 1 {
+		return NewError(api.BADREQUEST, fmt.Sprintf("max_open_reviews must be at least 1, got %d", *limit))
+	}
+	return nil
+}
+
+// SetUserMaxOpenReviews sets how many open reviews the user may hold at once.
+// A nil limit falls back to the team default.
+func (s *Service) SetUserMaxOpenReviews(ctx context.Context, userID string, limit *int) (api.User, error) {
+	if err := validateMaxOpenReviews(limit); err != nil {
+		return api.User{}, err
+	}
+	user, err := s.repo.SetUserMaxOpenReviews(ctx, userID, limit)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.User{}, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.User{}, err
+	}
+	return user, nil
+}
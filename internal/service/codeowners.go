@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/codeowners"
+	"pr-reviewer/internal/repo"
+)
+
+func (s *Service) GetCodeOwners(ctx context.Context, teamName string) (api.CodeOwners, error) {
+	owners, err := s.repo.GetCodeOwners(ctx, teamName)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.CodeOwners{}, NewError(api.NOTFOUND, "team not found")
+		}
+		return api.CodeOwners{}, err
+	}
+	file, err := codeowners.Parse(owners.Content)
+	if err != nil {
+		return api.CodeOwners{}, err
+	}
+	owners.Rules = codeOwnersRules(file)
+	return owners, nil
+}
+
+// SetCodeOwners replaces the team's ownership file. The file is parsed
+// up front so a broken upload never replaces a working one.
+func (s *Service) SetCodeOwners(ctx context.Context, teamName, content string) (api.CodeOwners, error) {
+	file, err := codeowners.Parse(content)
+	if err != nil {
+		return api.CodeOwners{}, NewError(api.BADREQUEST, "invalid codeowners file: "+err.Error())
+	}
+
+	owners, err := s.repo.SetCodeOwners(ctx, teamName, content)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.CodeOwners{}, NewError(api.NOTFOUND, "team not found")
+		}
+		return api.CodeOwners{}, err
+	}
+	owners.Rules = codeOwnersRules(file)
+	return owners, nil
+}
+
+// pickOwner picks one owner of the changed paths, per the author's team
+// ownership file, to review the pull request. owned reports whether the
+// paths have any owners at all, so a zero owner with owned set means none
+// of them can review right now.
+func (s *Service) pickOwner(ctx context.Context, settings api.TeamSettings, req assignmentRequest) (owner api.User, owned bool, err error) {
+	author := req.Author
+	stored, err := s.repo.GetCodeOwners(ctx, author.TeamName)
+	if err != nil || stored.Content == "" {
+		return api.User{}, false, err
+	}
+	file, err := codeowners.Parse(stored.Content)
+	if err != nil {
+		return api.User{}, false, err
+	}
+	owners := file.Owners(req.ChangedPaths)
+	if len(owners) == 0 {
+		return api.User{}, false, nil
+	}
+
+	var userIDs []string
+	var users []api.User
+	for _, o := range owners {
+		if o.User != "" {
+			userIDs = append(userIDs, o.User)
+			continue
+		}
+		members, err := s.repo.ListActiveUsersInTeam(ctx, o.Team)
+		if err != nil {
+			return api.User{}, true, err
+		}
+		users = append(users, members...)
+	}
+	if len(userIDs) > 0 {
+		listed, err := s.repo.ListActiveUsers(ctx, userIDs)
+		if err != nil {
+			return api.User{}, true, err
+		}
+		users = append(users, listed...)
+	}
+
+	seen := map[string]struct{}{author.UserId: {}}
+	var pool []api.User
+	for _, u := range users {
+		if _, skip := seen[u.UserId]; skip {
+			continue
+		}
+		seen[u.UserId] = struct{}{}
+		pool = append(pool, u)
+	}
+
+	candidates, err := s.eligibleReviewers(ctx, settings, pool, req.IgnoreCapacity)
+	if err != nil {
+		return api.User{}, true, err
+	}
+
+	picked, err := s.selectReviewers(ctx, settings, candidates, 1, req)
+	if err != nil || len(picked) == 0 {
+		return api.User{}, true, err
+	}
+	for _, u := range pool {
+		if u.UserId == picked[0] {
+			owner = u
+		}
+	}
+	return owner, true, nil
+}
+
+func codeOwnersRules(file *codeowners.File) []api.CodeOwnersRule {
+	rules := make([]api.CodeOwnersRule, 0, len(file.Rules))
+	for _, r := range file.Rules {
+		owners := make([]string, 0, len(r.Owners))
+		for _, o := range r.Owners {
+			owners = append(owners, o.String())
+		}
+		rules = append(rules, api.CodeOwnersRule{Pattern: r.Pattern, Owners: owners})
+	}
+	return rules
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// fallbackReviewers picks up to count reviewers from the team's fallback
+// teams, exhausting each team before moving on to the next one. Each
+// fallback team is selected from with its own settings; users in exclude
+// are never picked.
+func (s *Service) fallbackReviewers(ctx context.Context, settings api.TeamSettings, exclude map[string]struct{}, count int, req assignmentRequest) ([]string, error) {
+	if settings.FallbackTeams == nil {
+		return nil, nil
+	}
+
+	var picked []string
+	for _, teamName := range *settings.FallbackTeams {
+		if len(picked) >= count {
+			break
+		}
+
+		users, err := s.repo.ListActiveUsersInTeam(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		var pool []api.User
+		for _, u := range users {
+			if _, skip := exclude[u.UserId]; skip {
+				continue
+			}
+			pool = append(pool, u)
+		}
+		if len(pool) == 0 {
+			continue
+		}
+
+		fallback, err := s.teamSettings(ctx, teamName)
+		if err != nil {
+			if err == repo.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+
+		candidates, err := s.eligibleReviewers(ctx, fallback, pool, req.IgnoreCapacity)
+		if err != nil {
+			return nil, err
+		}
+
+		chosen, err := s.selectReviewers(ctx, fallback, candidates, count-len(picked), req)
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, chosen...)
+	}
+	return picked, nil
+}
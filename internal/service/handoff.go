@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// handoffPlan prepares a plan that reassigns open review slots of
+// deactivated members of team with the same exclusions as ReassignReviewer:
+// never the author, someone already reviewing the PR or someone at their
+// open review capacity. The team's mentoring policy is kept the same way
+// too: a slot whose PR would lose its only senior stays unassigned unless
+// a senior can take it. Load and rotation are tracked across slots so a
+// handoff doesn't pile up on one teammate.
+func (s *Service) handoffPlan(ctx context.Context, teamName string) (repo.HandoffPlan, error) {
+	settings, err := s.teamSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.ListActiveUsersInTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	limits := make(map[string]int, len(users))
+	levels := make(map[string]api.UserLevel, len(users))
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserId)
+		levels[u.UserId] = levelOf(u)
+		if limit, ok := capacityLimit(u, settings); ok {
+			limits[u.UserId] = limit
+		}
+	}
+
+	base := SelectionRequest{
+		Count:   1,
+		Weights: *settings.ReviewerWeights,
+	}
+	if settings.ReviewerStrategy == api.LeastLoaded || len(limits) > 0 {
+		if base.OpenReviews, err = s.repo.CountOpenReviews(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	if settings.ReviewerStrategy == api.RoundRobin {
+		if base.Cursor, err = s.repo.GetRotationCursor(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+	if base.OpenReviews == nil {
+		base.OpenReviews = map[string]int{}
+	}
+	selector := s.selectors[settings.ReviewerStrategy]
+
+	return func(active []string, slots []repo.ReviewSlot) {
+		req := base
+		taken := map[string]map[string]struct{}{}
+		added := map[string][]api.UserLevel{}
+		for i := range slots {
+			slot := &slots[i]
+
+			reviewers, ok := taken[slot.PullRequestID]
+			if !ok {
+				reviewers = make(map[string]struct{}, len(slot.ReviewerIDs))
+				for _, rid := range slot.ReviewerIDs {
+					reviewers[rid] = struct{}{}
+				}
+				taken[slot.PullRequestID] = reviewers
+			}
+
+			var candidates []string
+			for _, id := range active {
+				if id == slot.AuthorID {
+					continue
+				}
+				if _, skip := reviewers[id]; skip {
+					continue
+				}
+				if limit, ok := limits[id]; ok && req.OpenReviews[id] >= limit {
+					continue
+				}
+				candidates = append(candidates, id)
+			}
+			others := append(slices.Clip(slot.OtherLevels), added[slot.PullRequestID]...)
+			if level, strict := neededLevel(settings, slot.OldLevel, others); level != "" {
+				matching := slices.DeleteFunc(slices.Clone(candidates), func(id string) bool {
+					return levels[id] != level
+				})
+				if len(matching) > 0 || strict {
+					candidates = matching
+				}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+
+			req.Candidates = candidates
+			picked := selector.Select(s.rngFor(slot.PullRequestID), req)
+			if len(picked) == 0 {
+				continue
+			}
+
+			slot.NewReviewerID = picked[0]
+			reviewers[picked[0]] = struct{}{}
+			added[slot.PullRequestID] = append(added[slot.PullRequestID], levels[picked[0]])
+			req.OpenReviews[picked[0]]++
+			req.Cursor = picked[0]
+		}
+	}, nil
+}
+
+func handoffReport(slots []repo.ReviewSlot) api.ReassignmentReport {
+	report := api.ReassignmentReport{
+		Reassigned: []api.ReviewHandoff{},
+		Unassigned: []api.ReviewHandoff{},
+	}
+	for _, slot := range slots {
+		h := api.ReviewHandoff{
+			PullRequestId: slot.PullRequestID,
+			OldReviewerId: slot.OldReviewerID,
+		}
+		if slot.NewReviewerID == "" {
+			report.Unassigned = append(report.Unassigned, h)
+			continue
+		}
+		newID := slot.NewReviewerID
+		h.NewReviewerId = &newID
+		report.Reassigned = append(report.Reassigned, h)
+	}
+	return report
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// SetUserLevel sets the user's level used by the mentoring policy.
+func (s *Service) SetUserLevel(ctx context.Context, userID string, level api.UserLevel) (api.User, error) {
+	if err := validateLevel(level); err != nil {
+		return api.User{}, err
+	}
+	user, err := s.repo.SetUserLevel(ctx, userID, level)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.User{}, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.User{}, err
+	}
+	return user, nil
+}
+
+// mentoringPicks reserves slots for the team's mentoring policy: a senior
+// when require_senior is set and none of picked is senior, then a junior
+// shadow when junior_shadow is set and no junior is picked yet. users must
+// describe picked and candidates. A senior that can't be found is reported
+// as a warning; a missing junior shadow is not.
+func (s *Service) mentoringPicks(ctx context.Context, settings api.TeamSettings, users []api.User, picked, candidates []string, count int, req assignmentRequest) ([]string, []api.Warning, error) {
+	levels := make(map[string]api.UserLevel, len(users))
+	for _, u := range users {
+		levels[u.UserId] = levelOf(u)
+	}
+	has := func(ids []string, level api.UserLevel) bool {
+		for _, id := range ids {
+			if levels[id] == level {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []string
+	var warnings []api.Warning
+	reserve := func(level api.UserLevel) (bool, error) {
+		if len(picked)+len(out) >= count || has(picked, level) || has(out, level) {
+			return true, nil
+		}
+		chosen, err := s.selectReviewers(ctx, settings, withLevel(users, candidates, level), 1, req)
+		if err != nil || len(chosen) == 0 {
+			return false, err
+		}
+		out = append(out, chosen[0])
+		return true, nil
+	}
+
+	if *settings.RequireSenior {
+		ok, err := reserve(api.Senior)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			warnings = append(warnings, api.Warning{
+				Code:    string(api.NOCANDIDATE),
+				Message: fmt.Sprintf("no senior reviewer is available in team %s", settings.TeamName),
+			})
+		}
+	}
+	if *settings.JuniorShadow {
+		if _, err := reserve(api.Junior); err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, warnings, nil
+}
+
+// replacementLevel tells which level a replacement for oldUser must have so
+// that the reassignment keeps the team's mentoring policy. strict is set
+// when the old reviewer is the only senior under require_senior; a lone
+// junior shadow is only replaced by a junior when one is available.
+func (s *Service) replacementLevel(ctx context.Context, settings api.TeamSettings, pr api.PullRequest, oldUser api.User) (level api.UserLevel, strict bool, err error) {
+	if level, _ := neededLevel(settings, levelOf(oldUser), nil); level == "" {
+		return "", false, nil
+	}
+
+	var others []string
+	for _, rid := range pr.AssignedReviewers {
+		if rid != oldUser.UserId {
+			others = append(others, rid)
+		}
+	}
+	var levels []api.UserLevel
+	if len(others) > 0 {
+		users, err := s.repo.ListActiveUsers(ctx, others)
+		if err != nil {
+			return "", false, err
+		}
+		for _, u := range users {
+			levels = append(levels, levelOf(u))
+		}
+	}
+	level, strict = neededLevel(settings, levelOf(oldUser), levels)
+	return level, strict, nil
+}
+
+// neededLevel is replacementLevel for an old reviewer of level old on a
+// pull request whose other active reviewers have the levels others.
+func neededLevel(settings api.TeamSettings, old api.UserLevel, others []api.UserLevel) (level api.UserLevel, strict bool) {
+	switch {
+	case *settings.RequireSenior && old == api.Senior:
+		level, strict = api.Senior, true
+	case *settings.JuniorShadow && old == api.Junior:
+		level = api.Junior
+	default:
+		return "", false
+	}
+	if slices.Contains(others, level) {
+		return "", false
+	}
+	return level, strict
+}
+
+// withLevel returns the candidates whose user in users has the level.
+func withLevel(users []api.User, candidates []string, level api.UserLevel) []string {
+	levels := make(map[string]api.UserLevel, len(users))
+	for _, u := range users {
+		levels[u.UserId] = levelOf(u)
+	}
+	var out []string
+	for _, id := range candidates {
+		if levels[id] == level {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func levelOf(u api.User) api.UserLevel {
+	if u.Level == nil {
+		return api.Mid
+	}
+	return *u.Level
+}
+
+func validateLevel(level api.UserLevel) error {
+	switch level {
+	case api.Junior, api.Mid, api.Senior:
+		return nil
+	}
+	return NewError(api.BADREQUEST, fmt.Sprintf("unknown level %q", level))
+}
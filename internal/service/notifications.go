@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/notify"
+	"pr-reviewer/internal/repo"
+)
+
+// WebhookSender posts an event to a subscriber.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, m notify.Message) error
+}
+
+const (
+	webhookBatch       = 20
+	webhookLease       = time.Minute
+	webhookMaxAttempts = 10
+	webhookBaseDelay   = 10 * time.Second
+	webhookMaxDelay    = time.Hour
+)
+
+var webhookEventTypes = []api.WebhookEventType{
+	api.PullRequestCreated,
+	api.PullRequestReadyForReview,
+	api.PullRequestReviewerReassigned,
+	api.PullRequestReviewSubmitted,
+	api.PullRequestMerged,
+	api.PullRequestClosed,
+	api.PullRequestReopened,
+}
+
+// SetWebhookSender makes the outbox dispatcher queue events for
+// subscribers and the service send them through sender. Events are
+// dropped while no sender is set.
+func (s *Service) SetWebhookSender(sender WebhookSender) {
+	s.webhooks = sender
+}
+
+func (s *Service) CreateWebhookSubscription(ctx context.Context, req api.PostWebhooksSubscriptionsCreateJSONBody) (api.WebhookSubscription, error) {
+	u, err := url.Parse(req.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return api.WebhookSubscription{}, NewError(api.BADREQUEST, "url must be an absolute http or https URL")
+	}
+	if req.Secret == "" {
+		return api.WebhookSubscription{}, NewError(api.BADREQUEST, "secret must not be empty")
+	}
+
+	sub := repo.NewWebhookSubscription{URL: req.Url, Secret: req.Secret}
+	if req.Events != nil {
+		for _, e := range *req.Events {
+			if !slices.Contains(webhookEventTypes, e) {
+				return api.WebhookSubscription{}, NewError(api.BADREQUEST, fmt.Sprintf("unknown event %q", e))
+			}
+			if !slices.Contains(sub.Events, e) {
+				sub.Events = append(sub.Events, e)
+			}
+		}
+	}
+	return s.repo.CreateWebhookSubscription(ctx, sub)
+}
+
+func (s *Service) ListWebhookSubscriptions(ctx context.Context) ([]api.WebhookSubscription, error) {
+	return s.repo.ListWebhookSubscriptions(ctx)
+}
+
+func (s *Service) DeleteWebhookSubscription(ctx context.Context, id int64) (api.WebhookSubscription, error) {
+	sub, err := s.repo.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.WebhookSubscription{}, NewError(api.NOTFOUND, "subscription not found")
+		}
+		return api.WebhookSubscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *Service) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status *api.WebhookDeliveryStatus) ([]api.WebhookDelivery, error) {
+	var filter api.WebhookDeliveryStatus
+	if status != nil {
+		switch *status {
+		case api.Pending, api.Delivered, api.Dead:
+			filter = *status
+		default:
+			return nil, NewError(api.BADREQUEST, fmt.Sprintf("unknown delivery status %q", *status))
+		}
+	}
+	deliveries, err := s.repo.ListWebhookDeliveries(ctx, subscriptionID, filter)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return nil, NewError(api.NOTFOUND, "subscription not found")
+		}
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues the delivery again with fresh attempts, whatever
+// became of it.
+func (s *Service) RedeliverWebhook(ctx context.Context, deliveryID int64) (api.WebhookDelivery, error) {
+	d, err := s.repo.RedeliverWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.WebhookDelivery{}, NewError(api.NOTFOUND, "delivery not found")
+		}
+		return api.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// RunWebhookDelivery sends queued events every interval until ctx is
+// done.
+func (s *Service) RunWebhookDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.DeliverWebhooks(ctx)
+			if err != nil {
+				log.Printf("webhook delivery: %v", err)
+			}
+			if err != nil || n < webhookBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverWebhooks sends one batch of due events concurrently and returns
+// how many it took. A failed delivery is retried with exponential backoff
+// until the subscriber refuses it for good or it runs out of attempts;
+// then it is dead-lettered until redelivered.
+func (s *Service) DeliverWebhooks(ctx context.Context) (int, error) {
+	if s.webhooks == nil {
+		return 0, nil
+	}
+	jobs, err := s.repo.ClaimWebhookDeliveries(ctx, webhookBatch, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.deliverWebhook(ctx, job)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return len(jobs), err
+		}
+	}
+	return len(jobs), nil
+}
+
+func (s *Service) deliverWebhook(ctx context.Context, job repo.WebhookDeliveryJob) error {
+	sendErr := s.webhooks.Send(ctx, job.URL, job.Secret, notify.Message{
+		DeliveryID: job.ID,
+		Event:      string(job.Event),
+		Payload:    job.Payload,
+	})
+	switch {
+	case sendErr == nil:
+		return s.repo.CompleteWebhookDelivery(ctx, job.ID)
+	case !temporary(sendErr) || job.Attempts >= webhookMaxAttempts:
+		log.Printf("webhook delivery %d to subscription %d failed after %d attempts: %v", job.ID, job.SubscriptionID, job.Attempts, sendErr)
+		return s.repo.DeadLetterWebhookDelivery(ctx, job.ID, sendErr.Error())
+	default:
+		return s.repo.RetryWebhookDelivery(ctx, job.ID, backoff(webhookBaseDelay, webhookMaxDelay, job.Attempts), sendErr.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// OutboxBatch is how many outbox messages DispatchOutbox takes at once.
+const OutboxBatch = 50
+
+// DispatchOutbox fans one batch of events recorded by the repository out
+// to webhook subscribers and Git hosts and returns how many it took. Any
+// number of replicas may call it concurrently.
+func (s *Service) DispatchOutbox(ctx context.Context) (int, error) {
+	return s.repo.DispatchOutbox(ctx, OutboxBatch, s.routeOutbox)
+}
+
+func (s *Service) routeOutbox(m repo.OutboxMessage) (repo.OutboxRoute, error) {
+	var ev api.WebhookEvent
+	if err := json.Unmarshal(m.Payload, &ev); err != nil {
+		return repo.OutboxRoute{}, fmt.Errorf("decode %s event: %w", m.Topic, err)
+	}
+
+	rt := repo.OutboxRoute{Webhooks: s.webhooks != nil}
+	switch m.Topic {
+	case api.PullRequestCreated, api.PullRequestReadyForReview:
+		rt.SyncAddIDs = ev.PullRequest.AssignedReviewers
+	case api.PullRequestReviewerReassigned:
+		if h := ev.Reassignment; h != nil {
+			if h.NewReviewerId != nil {
+				rt.SyncAddIDs = []string{*h.NewReviewerId}
+			}
+			rt.SyncRemoveIDs = []string{h.OldReviewerId}
+		}
+	}
+	for provider := range s.requesters {
+		rt.SyncProviders = append(rt.SyncProviders, provider)
+	}
+	return rt, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// lockedSource guards a rand.Source so one *rand.Rand can be shared by
+// concurrent requests.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func newLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// SetSeed reseeds the shared random source so that a sequence of
+// assignments can be reproduced.
+func (s *Service) SetSeed(seed int64) {
+	s.seed = seed
+	s.rng = newLockedRand(seed)
+}
+
+// SetDeterministic switches per-PR deterministic selection on or off. When
+// on, reviewers of a pull request are drawn from a source seeded with a
+// hash of its id, so replaying the same request picks the same reviewers
+// as long as the team and its load haven't changed.
+func (s *Service) SetDeterministic(on bool) {
+	s.deterministic = on
+}
+
+// rngFor returns the random source to pick reviewers of pull request prID
+// with.
+func (s *Service) rngFor(prID string) *rand.Rand {
+	if !s.deterministic || prID == "" {
+		return s.rng
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(prID))
+	return rand.New(rand.NewSource(int64(h.Sum64()) ^ s.seed))
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// ReviewRequester mirrors reviewer changes onto a Git host. Pull requests
+// are identified by the ids the host's webhooks give them and reviewers by
+// their host logins.
+type ReviewRequester interface {
+	RequestReviewers(ctx context.Context, prID string, logins []string) error
+	RemoveReviewers(ctx context.Context, prID string, logins []string) error
+}
+
+const (
+	reviewerSyncBatch       = 20
+	reviewerSyncLease       = time.Minute
+	reviewerSyncMaxAttempts = 8
+	reviewerSyncBaseDelay   = 10 * time.Second
+	reviewerSyncMaxDelay    = time.Hour
+)
+
+// SetReviewRequester makes reviewer changes on pull requests mirrored from
+// provider be pushed back to it through r.
+func (s *Service) SetReviewRequester(provider api.GitProvider, r ReviewRequester) {
+	if s.requesters == nil {
+		s.requesters = map[api.GitProvider]ReviewRequester{}
+	}
+	s.requesters[provider] = r
+}
+
+// RunReviewerSync delivers queued reviewer changes every interval until
+// ctx is done.
+func (s *Service) RunReviewerSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.SyncReviewers(ctx)
+			if err != nil {
+				log.Printf("reviewer sync: %v", err)
+			}
+			if err != nil || n < reviewerSyncBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncReviewers delivers one batch of due reviewer changes and returns
+// how many it took. A failed delivery is retried with exponential backoff
+// unless the host refused it for good or it ran out of attempts, in which
+// case it is marked failed.
+func (s *Service) SyncReviewers(ctx context.Context) (int, error) {
+	jobs, err := s.repo.ClaimReviewerSyncJobs(ctx, reviewerSyncBatch, reviewerSyncLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		deliverErr := s.deliverReviewerSync(ctx, job)
+		switch {
+		case deliverErr == nil:
+			err = s.repo.CompleteReviewerSyncJob(ctx, job.ID)
+		case !temporary(deliverErr) || job.Attempts >= reviewerSyncMaxAttempts:
+			log.Printf("reviewer sync for %s failed after %d attempts: %v", job.PullRequestID, job.Attempts, deliverErr)
+			err = s.repo.FailReviewerSyncJob(ctx, job.ID, deliverErr.Error())
+		default:
+			err = s.repo.RetryReviewerSyncJob(ctx, job.ID, backoff(reviewerSyncBaseDelay, reviewerSyncMaxDelay, job.Attempts), deliverErr.Error())
+		}
+		if err != nil {
+			return len(jobs), err
+		}
+	}
+	return len(jobs), nil
+}
+
+func (s *Service) deliverReviewerSync(ctx context.Context, job repo.ReviewerSyncJob) error {
+	requester, ok := s.requesters[job.Provider]
+	if !ok {
+		return permanentError{msg: "no review requester for " + string(job.Provider)}
+	}
+
+	logins, err := s.repo.ExternalLoginsOf(ctx, job.Provider, slices.Concat(job.RemoveIDs, job.AddIDs))
+	if err != nil {
+		return err
+	}
+	toLogins := func(ids []string) []string {
+		var out []string
+		for _, id := range ids {
+			if login, ok := logins[id]; ok {
+				out = append(out, login)
+			}
+		}
+		return out
+	}
+
+	if remove := toLogins(job.RemoveIDs); len(remove) > 0 {
+		if err := requester.RemoveReviewers(ctx, job.PullRequestID, remove); err != nil {
+			return err
+		}
+	}
+	if add := toLogins(job.AddIDs); len(add) > 0 {
+		if err := requester.RequestReviewers(ctx, job.PullRequestID, add); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type permanentError struct {
+	msg string
+}
+
+func (e permanentError) Error() string   { return e.msg }
+func (e permanentError) Temporary() bool { return false }
+
+// temporary treats errors as worth retrying unless they say otherwise, so
+// network failures are retried.
+func temporary(err error) bool {
+	var t interface{ Temporary() bool }
+	if errors.As(err, &t) {
+		return t.Temporary()
+	}
+	return true
+}
+
+// backoff doubles base with every attempt after the first, up to max.
+func backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base << (attempts - 1)
+	if delay <= 0 || delay > max {
+		return max
+	}
+	return delay
+}
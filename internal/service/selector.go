@@ -0,0 +1,182 @@
+package service
+
+import (
+	"math/rand"
+	"sort"
+)
+
+type SelectionRequest struct {
+	Candidates []string
+	Count      int
+	// OpenReviews holds the number of OPEN pull requests each candidate
+	// currently reviews; candidates missing from the map have none.
+	OpenReviews map[string]int
+	// Weights holds per-candidate weights; candidates missing from the
+	// map weigh 1.
+	Weights map[string]int
+	// Cursor is the user_id the team's rotation assigned last.
+	Cursor string
+	// Overlap holds how many of the pull request's labels match each
+	// candidate's tags; candidates missing from the map match none.
+	Overlap map[string]int
+	// RecentReviews holds how many of the author's recent pull requests
+	// each candidate reviewed; candidates missing from the map reviewed
+	// none.
+	RecentReviews map[string]int
+}
+
+type ReviewerSelector interface {
+	Select(rng *rand.Rand, req SelectionRequest) []string
+}
+
+type RandomSelector struct{}
+
+func (RandomSelector) Select(rng *rand.Rand, req SelectionRequest) []string {
+	return pickRandom(rng, req.Candidates, req.Count)
+}
+
+// LeastLoadedSelector prefers candidates with the fewest open reviews,
+// breaking ties randomly.
+type LeastLoadedSelector struct{}
+
+func (LeastLoadedSelector) Select(rng *rand.Rand, req SelectionRequest) []string {
+	shuffled := make([]string, len(req.Candidates))
+	for i, j := range rng.Perm(len(req.Candidates)) {
+		shuffled[i] = req.Candidates[j]
+	}
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return req.OpenReviews[shuffled[i]] < req.OpenReviews[shuffled[j]]
+	})
+	if len(shuffled) > req.Count {
+		shuffled = shuffled[:req.Count]
+	}
+	return shuffled
+}
+
+// SkillMatchSelector prefers candidates whose tags match the most labels
+// of the pull request; ties and candidates without a match are picked at
+// random.
+type SkillMatchSelector struct{}
+
+func (SkillMatchSelector) Select(rng *rand.Rand, req SelectionRequest) []string {
+	shuffled := make([]string, len(req.Candidates))
+	for i, j := range rng.Perm(len(req.Candidates)) {
+		shuffled[i] = req.Candidates[j]
+	}
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return req.Overlap[shuffled[i]] > req.Overlap[shuffled[j]]
+	})
+	if len(shuffled) > req.Count {
+		shuffled = shuffled[:req.Count]
+	}
+	return shuffled
+}
+
+// RoundRobinSelector walks candidates in user_id order, starting right
+// after the request's cursor.
+type RoundRobinSelector struct{}
+
+func (RoundRobinSelector) Select(_ *rand.Rand, req SelectionRequest) []string {
+	return rotate(req.Candidates, req.Cursor, req.Count)
+}
+
+func rotate(candidates []string, cursor string, count int) []string {
+	ordered := make([]string, len(candidates))
+	copy(ordered, candidates)
+	sort.Strings(ordered)
+
+	start := sort.Search(len(ordered), func(i int) bool {
+		return ordered[i] > cursor
+	})
+
+	if count > len(ordered) {
+		count = len(ordered)
+	}
+	out := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		out = append(out, ordered[(start+i)%len(ordered)])
+	}
+	return out
+}
+
+// WeightedSelector draws candidates at random proportionally to their
+// weight, without replacement. Candidates with zero weight are never picked.
+type WeightedSelector struct{}
+
+func (WeightedSelector) Select(rng *rand.Rand, req SelectionRequest) []string {
+	pool := make([]string, 0, len(req.Candidates))
+	weights := make([]int, 0, len(req.Candidates))
+	total := 0
+	for _, c := range req.Candidates {
+		w, ok := req.Weights[c]
+		if !ok {
+			w = 1
+		}
+		if w <= 0 {
+			continue
+		}
+		pool = append(pool, c)
+		weights = append(weights, w)
+		total += w
+	}
+
+	var out []string
+	for len(out) < req.Count && total > 0 {
+		n := rng.Intn(total)
+		i := 0
+		for n >= weights[i] {
+			n -= weights[i]
+			i++
+		}
+		out = append(out, pool[i])
+		total -= weights[i]
+		pool = append(pool[:i], pool[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return out
+}
+
+// DiverseSelector draws candidates at random, without replacement, with
+// a candidate who reviewed k of the author's recent pull requests being
+// k+1 times less likely to be drawn than one who reviewed none.
+type DiverseSelector struct{}
+
+func (DiverseSelector) Select(rng *rand.Rand, req SelectionRequest) []string {
+	pool := make([]string, len(req.Candidates))
+	copy(pool, req.Candidates)
+	weights := make([]float64, len(pool))
+	total := 0.0
+	for i, c := range pool {
+		weights[i] = 1 / float64(1+req.RecentReviews[c])
+		total += weights[i]
+	}
+
+	var out []string
+	for len(out) < req.Count && len(pool) > 0 {
+		n := rng.Float64() * total
+		i := 0
+		for i < len(pool)-1 && n >= weights[i] {
+			n -= weights[i]
+			i++
+		}
+		out = append(out, pool[i])
+		total -= weights[i]
+		pool = append(pool[:i], pool[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return out
+}
+
+func pickRandom(rng *rand.Rand, items []string, max int) []string {
+	if len(items) <= max {
+		out := make([]string, len(items))
+		copy(out, items)
+		return out
+	}
+	out := make([]string, max)
+	perm := rng.Perm(len(items))
+	for i := 0; i < max; i++ {
+		out[i] = items[perm[i]]
+	}
+	return out
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"slices"
+	"strings"
 	"time"
 
 	"pr-reviewer/internal/api"
@@ -11,35 +13,112 @@ import (
 )
 
 type Repository interface {
-	CreateTeamWithMembers(ctx context.Context, team api.Team) (api.Team, error)
+	CreateTeamWithMembers(ctx context.Context, team api.Team, moveMembers bool, plans map[string]repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error)
 	GetTeam(ctx context.Context, teamName string) (api.Team, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []api.TeamMember, plan repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error)
+	RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, plan repo.HandoffPlan) (api.Team, []api.User, []repo.ReviewSlot, error)
+	DeleteTeam(ctx context.Context, teamName string) ([]api.User, error)
+	MoveUserToTeam(ctx context.Context, userID, teamName string, plan repo.HandoffPlan) (api.User, string, []repo.ReviewSlot, error)
+	ListTeamMoves(ctx context.Context, userID string) ([]api.TeamMove, error)
+
+	AddUnavailability(ctx context.Context, window api.UnavailabilityWindow, plan repo.HandoffPlan) (api.UnavailabilityWindow, []repo.ReviewSlot, error)
+	ListUnavailability(ctx context.Context, userID string) ([]api.UnavailabilityWindow, error)
+	DeleteUnavailability(ctx context.Context, id int64) (api.UnavailabilityWindow, error)
+	ListDueHandoffs(ctx context.Context, limit int) ([]repo.DueHandoff, error)
+	HandOffDueWindow(ctx context.Context, windowID int64, teamName string, plan repo.HandoffPlan) ([]repo.ReviewSlot, error)
 
 	SetUserActive(ctx context.Context, userID string, isActive bool) (api.User, error)
+	SetUserMaxOpenReviews(ctx context.Context, userID string, limit *int) (api.User, error)
+	SetUserTags(ctx context.Context, userID string, tags []string) (api.User, error)
+	SetUserLevel(ctx context.Context, userID string, level api.UserLevel) (api.User, error)
+	GetCodeOwners(ctx context.Context, teamName string) (api.CodeOwners, error)
+	SetCodeOwners(ctx context.Context, teamName, content string) (api.CodeOwners, error)
+	DeactivateUser(ctx context.Context, userID string, plan repo.HandoffPlan) (api.User, []repo.ReviewSlot, error)
+	DeactivateUsers(ctx context.Context, teamName string, userIDs []string, allExcept bool, plan repo.HandoffPlan) ([]api.User, []repo.ReviewSlot, error)
 	GetUser(ctx context.Context, userID string) (api.User, error)
 	ListActiveUsersInTeam(ctx context.Context, teamName string) ([]api.User, error)
+	ListActiveUsers(ctx context.Context, userIDs []string) ([]api.User, error)
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+	CountRecentReviews(ctx context.Context, authorID string, n int) (map[string]int, error)
+
+	GetTeamSettings(ctx context.Context, teamName string) (api.TeamSettings, error)
+	UpsertTeamSettings(ctx context.Context, settings api.TeamSettings) (api.TeamSettings, error)
 
 	PullRequestExists(ctx context.Context, prID string) (bool, error)
-	CreatePullRequest(ctx context.Context, prID, prName, authorID string, reviewerIDs []string) (api.PullRequest, error)
+	CreatePullRequest(ctx context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error)
+	MarkPullRequestReady(ctx context.Context, prID string, assignment repo.Assignment) (api.PullRequest, error)
+	MarkPullRequestDraft(ctx context.Context, prID string) (api.PullRequest, error)
 	GetPullRequest(ctx context.Context, prID string) (api.PullRequest, error)
-	MarkPullRequestMerged(ctx context.Context, prID string) (api.PullRequest, error)
+	MarkPullRequestMerged(ctx context.Context, prID string, force bool) (api.PullRequest, error)
+	MarkPullRequestClosed(ctx context.Context, prID string) (api.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, prID string, candidates []string, plan repo.HandoffPlan) (api.PullRequest, []repo.ReviewSlot, error)
 	ReplaceReviewer(ctx context.Context, prID, oldUserID, newUserID string) error
-	ListUserReviewPRs(ctx context.Context, userID string) ([]api.PullRequestShort, error)
+	SetReviewDecision(ctx context.Context, prID, reviewerID string, decision api.ReviewDecision) error
+	ListUserReviewPRs(ctx context.Context, userID string, pendingOnly bool) ([]api.PullRequestShort, error)
+
+	GetRotationCursor(ctx context.Context, teamName string) (string, error)
+
+	SetExternalLogin(ctx context.Context, login api.ExternalLogin) (api.ExternalLogin, error)
+	DeleteExternalLogin(ctx context.Context, provider api.GitProvider, login string) (api.ExternalLogin, error)
+	ListExternalLogins(ctx context.Context, provider api.GitProvider) ([]api.ExternalLogin, error)
+	ExternalLoginUser(ctx context.Context, provider api.GitProvider, login string) (string, error)
+	ExternalLoginsOf(ctx context.Context, provider api.GitProvider, userIDs []string) (map[string]string, error)
+
+	ClaimReviewerSyncJobs(ctx context.Context, limit int, lease time.Duration) ([]repo.ReviewerSyncJob, error)
+	CompleteReviewerSyncJob(ctx context.Context, id int64) error
+	RetryReviewerSyncJob(ctx context.Context, id int64, delay time.Duration, lastErr string) error
+	FailReviewerSyncJob(ctx context.Context, id int64, lastErr string) error
+
+	CreateWebhookSubscription(ctx context.Context, sub repo.NewWebhookSubscription) (api.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]api.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) (api.WebhookSubscription, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repo.WebhookDeliveryJob, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64) error
+	RetryWebhookDelivery(ctx context.Context, id int64, delay time.Duration, lastErr string) error
+	DeadLetterWebhookDelivery(ctx context.Context, id int64, lastErr string) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status api.WebhookDeliveryStatus) ([]api.WebhookDelivery, error)
+	RedeliverWebhookDelivery(ctx context.Context, id int64) (api.WebhookDelivery, error)
+
+	DispatchOutbox(ctx context.Context, limit int, route func(repo.OutboxMessage) (repo.OutboxRoute, error)) (int, error)
 }
 
 var _ Repository = (*repo.Repo)(nil)
 
 type Service struct {
-	repo Repository
-	rng  *rand.Rand
+	repo            Repository
+	rng             *rand.Rand
+	seed            int64
+	deterministic   bool
+	requesters      map[api.GitProvider]ReviewRequester
+	webhooks        WebhookSender
+	selectors       map[api.ReviewerStrategy]ReviewerSelector
+	defaultStrategy api.ReviewerStrategy
 }
 
 func NewService(r Repository) *Service {
 	return &Service{
 		repo: r,
-		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:  newLockedRand(time.Now().UnixNano()),
+		selectors: map[api.ReviewerStrategy]ReviewerSelector{
+			api.Random:      RandomSelector{},
+			api.RoundRobin:  RoundRobinSelector{},
+			api.LeastLoaded: LeastLoadedSelector{},
+			api.Weighted:    WeightedSelector{},
+			api.SkillMatch:  SkillMatchSelector{},
+			api.Diverse:     DiverseSelector{},
+		},
+		defaultStrategy: api.LeastLoaded,
 	}
 }
 
+func (s *Service) SetDefaultStrategy(strategy string) error {
+	if _, ok := s.selectors[api.ReviewerStrategy(strategy)]; !ok {
+		return fmt.Errorf("unknown reviewer strategy %q", strategy)
+	}
+	s.defaultStrategy = api.ReviewerStrategy(strategy)
+	return nil
+}
+
 type Error struct {
 	Code api.ErrorResponseErrorCode
 	Msg  string
@@ -53,15 +132,44 @@ func NewError(code api.ErrorResponseErrorCode, msg string) *Error {
 	return &Error{Code: code, Msg: msg}
 }
 
-func (s *Service) CreateTeam(ctx context.Context, team api.Team) (api.Team, error) {
-	created, err := s.repo.CreateTeamWithMembers(ctx, team)
+// CreateTeam creates a team with its members. Users that already belong to
+// another team are only moved over when moveMembers is set, and their open
+// reviews are handed over to the teams they leave.
+func (s *Service) CreateTeam(ctx context.Context, team api.Team, moveMembers bool) (api.Team, api.ReassignmentReport, error) {
+	if err := prepareMembers(team.Members); err != nil {
+		return api.Team{}, api.ReassignmentReport{}, err
+	}
+
+	plans := map[string]repo.HandoffPlan{}
+	if moveMembers {
+		for _, m := range team.Members {
+			user, err := s.repo.GetUser(ctx, m.UserId)
+			if err == repo.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return api.Team{}, api.ReassignmentReport{}, err
+			}
+			if _, ok := plans[user.TeamName]; ok || user.TeamName == "" {
+				continue
+			}
+			if plans[user.TeamName], err = s.handoffPlan(ctx, user.TeamName); err != nil {
+				return api.Team{}, api.ReassignmentReport{}, err
+			}
+		}
+	}
+
+	created, slots, err := s.repo.CreateTeamWithMembers(ctx, team, moveMembers, plans)
 	if err != nil {
-		if err == repo.ErrTeamExists {
-			return api.Team{}, NewError(api.TEAMEXISTS, "team already exists")
+		switch err {
+		case repo.ErrTeamExists:
+			return api.Team{}, api.ReassignmentReport{}, NewError(api.TEAMEXISTS, "team already exists")
+		case repo.ErrUserInOtherTeam:
+			return api.Team{}, api.ReassignmentReport{}, NewError(api.USERINOTHERTEAM, "some users are members of another team; pass move_members to move them")
 		}
-		return api.Team{}, err
+		return api.Team{}, api.ReassignmentReport{}, err
 	}
-	return created, nil
+	return created, handoffReport(slots), nil
 }
 
 func (s *Service) GetTeam(ctx context.Context, teamName string) (api.Team, error) {
@@ -75,57 +183,553 @@ func (s *Service) GetTeam(ctx context.Context, teamName string) (api.Team, error
 	return team, nil
 }
 
-func (s *Service) SetUserActive(ctx context.Context, userID string, isActive bool) (api.User, error) {
-	user, err := s.repo.SetUserActive(ctx, userID, isActive)
+// AddTeamMembers creates or updates members of an existing team. Members
+// it deactivates hand their open reviews over to the rest of the team.
+func (s *Service) AddTeamMembers(ctx context.Context, team api.Team) (api.Team, api.ReassignmentReport, error) {
+	if err := prepareMembers(team.Members); err != nil {
+		return api.Team{}, api.ReassignmentReport{}, err
+	}
+
+	var plan repo.HandoffPlan
+	if slices.ContainsFunc(team.Members, func(m api.TeamMember) bool { return !m.IsActive }) {
+		var err error
+		if plan, err = s.handoffPlan(ctx, team.TeamName); err != nil {
+			if err == repo.ErrNotFound {
+				return api.Team{}, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
+			}
+			return api.Team{}, api.ReassignmentReport{}, err
+		}
+	}
+
+	updated, slots, err := s.repo.AddTeamMembers(ctx, team.TeamName, team.Members, plan)
+	if err != nil {
+		switch err {
+		case repo.ErrNotFound:
+			return api.Team{}, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
+		case repo.ErrUserInOtherTeam:
+			return api.Team{}, api.ReassignmentReport{}, NewError(api.USERINOTHERTEAM, "some users are members of another team")
+		}
+		return api.Team{}, api.ReassignmentReport{}, err
+	}
+	return updated, handoffReport(slots), nil
+}
+
+// RemoveTeamMembers detaches users from the team, leaving them inactive and
+// without a team, and hands their open reviews over to the remaining
+// members. Authors of open pull requests can't be removed.
+func (s *Service) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string) (api.Team, []api.User, api.ReassignmentReport, error) {
+	if len(userIDs) == 0 {
+		return api.Team{}, nil, api.ReassignmentReport{}, NewError(api.BADREQUEST, "user_ids must not be empty")
+	}
+
+	plan, err := s.handoffPlan(ctx, teamName)
 	if err != nil {
 		if err == repo.ErrNotFound {
-			return api.User{}, NewError(api.NOTFOUND, "user not found")
+			return api.Team{}, nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
 		}
-		return api.User{}, err
+		return api.Team{}, nil, api.ReassignmentReport{}, err
 	}
-	return user, nil
+
+	team, removed, slots, err := s.repo.RemoveTeamMembers(ctx, teamName, uniqueIDs(userIDs), plan)
+	if err != nil {
+		switch err {
+		case repo.ErrNotFound:
+			return api.Team{}, nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
+		case repo.ErrNotTeamMember:
+			return api.Team{}, nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "some users are not members of the team")
+		case repo.ErrTeamHasOpenPRs:
+			return api.Team{}, nil, api.ReassignmentReport{}, NewError(api.TEAMHASOPENPRS, "some users still author open pull requests")
+		}
+		return api.Team{}, nil, api.ReassignmentReport{}, err
+	}
+	return team, removed, handoffReport(slots), nil
 }
 
-func (s *Service) CreatePullRequest(ctx context.Context, id, name, authorID string) (api.PullRequest, error) {
-	exists, err := s.repo.PullRequestExists(ctx, id)
+// DeleteTeam drops a team whose members have no open pull requests or
+// reviews; the members stay as inactive users without a team.
+func (s *Service) DeleteTeam(ctx context.Context, teamName string) ([]api.User, error) {
+	detached, err := s.repo.DeleteTeam(ctx, teamName)
 	if err != nil {
-		return api.PullRequest{}, err
+		switch err {
+		case repo.ErrNotFound:
+			return nil, NewError(api.NOTFOUND, "team not found")
+		case repo.ErrTeamHasOpenPRs:
+			return nil, NewError(api.TEAMHASOPENPRS, "team members still have open pull requests or reviews")
+		}
+		return nil, err
+	}
+	return detached, nil
+}
+
+// MoveUserToTeam moves a user to another team. With handOff their open
+// reviews go to the remaining active members of the old team, and the
+// returned report describes the outcome; otherwise the report is nil.
+func (s *Service) MoveUserToTeam(ctx context.Context, req api.PostUsersMoveTeamJSONBody) (api.User, string, *api.ReassignmentReport, error) {
+	handOff := req.HandOffReviews != nil && *req.HandOffReviews
+
+	var plan repo.HandoffPlan
+	if handOff {
+		user, err := s.repo.GetUser(ctx, req.UserId)
+		if err != nil {
+			if err == repo.ErrNotFound {
+				return api.User{}, "", nil, NewError(api.NOTFOUND, "user not found")
+			}
+			return api.User{}, "", nil, err
+		}
+		if user.TeamName != "" && user.TeamName != req.TeamName {
+			if plan, err = s.handoffPlan(ctx, user.TeamName); err != nil {
+				return api.User{}, "", nil, err
+			}
+		}
+	}
+
+	user, fromTeam, slots, err := s.repo.MoveUserToTeam(ctx, req.UserId, req.TeamName, plan)
+	if err != nil {
+		switch err {
+		case repo.ErrUserNotFound:
+			return api.User{}, "", nil, NewError(api.NOTFOUND, "user not found")
+		case repo.ErrNotFound:
+			return api.User{}, "", nil, NewError(api.NOTFOUND, "team not found")
+		}
+		return api.User{}, "", nil, err
+	}
+
+	if !handOff {
+		return user, fromTeam, nil, nil
+	}
+	report := handoffReport(slots)
+	return user, fromTeam, &report, nil
+}
+
+func (s *Service) ListTeamMoves(ctx context.Context, userID string) ([]api.TeamMove, error) {
+	moves, err := s.repo.ListTeamMoves(ctx, userID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return nil, NewError(api.NOTFOUND, "user not found")
+		}
+		return nil, err
+	}
+	return moves, nil
+}
+
+// SetUserActive flips the user's active flag. Deactivation also hands the
+// user's open reviews over to active teammates and reports the outcome;
+// the report is nil on activation.
+func (s *Service) SetUserActive(ctx context.Context, userID string, isActive bool) (api.User, *api.ReassignmentReport, error) {
+	if isActive {
+		user, err := s.repo.SetUserActive(ctx, userID, true)
+		if err != nil {
+			if err == repo.ErrNotFound {
+				return api.User{}, nil, NewError(api.NOTFOUND, "user not found")
+			}
+			return api.User{}, nil, err
+		}
+		return user, nil, nil
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.User{}, nil, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.User{}, nil, err
+	}
+	if user.TeamName == "" {
+		user, err = s.repo.SetUserActive(ctx, userID, false)
+		if err != nil {
+			return api.User{}, nil, err
+		}
+		return user, &api.ReassignmentReport{Reassigned: []api.ReviewHandoff{}, Unassigned: []api.ReviewHandoff{}}, nil
+	}
+
+	plan, err := s.handoffPlan(ctx, user.TeamName)
+	if err != nil {
+		return api.User{}, nil, err
+	}
+
+	user, slots, err := s.repo.DeactivateUser(ctx, userID, plan)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.User{}, nil, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.User{}, nil, err
+	}
+
+	report := handoffReport(slots)
+	return user, &report, nil
+}
+
+// DeactivateTeamUsers deactivates the listed team members, or everyone but
+// them with AllExcept, atomically and hands their open reviews over to the
+// members that stay active.
+func (s *Service) DeactivateTeamUsers(ctx context.Context, req api.PostTeamDeactivateUsersJSONBody) ([]api.User, api.ReassignmentReport, error) {
+	allExcept := req.AllExcept != nil && *req.AllExcept
+	if len(req.UserIds) == 0 && !allExcept {
+		return nil, api.ReassignmentReport{}, NewError(api.BADREQUEST, "user_ids must not be empty")
+	}
+
+	plan, err := s.handoffPlan(ctx, req.TeamName)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
+		}
+		return nil, api.ReassignmentReport{}, err
+	}
+
+	users, slots, err := s.repo.DeactivateUsers(ctx, req.TeamName, uniqueIDs(req.UserIds), allExcept, plan)
+	if err != nil {
+		switch err {
+		case repo.ErrNotFound:
+			return nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "team not found")
+		case repo.ErrNotTeamMember:
+			return nil, api.ReassignmentReport{}, NewError(api.NOTFOUND, "some users are not members of the team")
+		}
+		return nil, api.ReassignmentReport{}, err
+	}
+	if users == nil {
+		users = []api.User{}
+	}
+
+	return users, handoffReport(slots), nil
+}
+
+func (s *Service) CreatePullRequest(ctx context.Context, req api.PostPullRequestCreateJSONBody) (api.PullRequest, []api.Warning, error) {
+	return s.createPullRequest(ctx, req, "")
+}
+
+// createPullRequest creates a pull request that mirrors one on provider,
+// or a standalone one when provider is empty.
+func (s *Service) createPullRequest(ctx context.Context, req api.PostPullRequestCreateJSONBody, provider api.GitProvider) (api.PullRequest, []api.Warning, error) {
+	if req.ReviewersCount != nil {
+		if err := validateReviewersCount(*req.ReviewersCount); err != nil {
+			return api.PullRequest{}, nil, err
+		}
+	}
+
+	exists, err := s.repo.PullRequestExists(ctx, req.PullRequestId)
+	if err != nil {
+		return api.PullRequest{}, nil, err
 	}
 	if exists {
-		return api.PullRequest{}, NewError(api.PREXISTS, "pull request already exists")
+		return api.PullRequest{}, nil, NewError(api.PREXISTS, "pull request already exists")
 	}
 
-	author, err := s.repo.GetUser(ctx, authorID)
+	author, err := s.repo.GetUser(ctx, req.AuthorId)
 	if err != nil {
 		if err == repo.ErrNotFound {
-			return api.PullRequest{}, NewError(api.NOTFOUND, "author not found")
+			return api.PullRequest{}, nil, NewError(api.NOTFOUND, "author not found")
+		}
+		return api.PullRequest{}, nil, err
+	}
+
+	newPR := repo.NewPullRequest{
+		ID:       req.PullRequestId,
+		Name:     req.PullRequestName,
+		AuthorID: req.AuthorId,
+		Draft:    req.Draft != nil && *req.Draft,
+		Provider: provider,
+	}
+	if req.Labels != nil {
+		if newPR.Labels, err = normalizeTags(*req.Labels, "labels"); err != nil {
+			return api.PullRequest{}, nil, err
+		}
+	}
+	if req.ChangedPaths != nil {
+		for _, path := range *req.ChangedPaths {
+			path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+			if path == "" {
+				return api.PullRequest{}, nil, NewError(api.BADREQUEST, "changed_paths must not contain empty paths")
+			}
+			newPR.ChangedPaths = append(newPR.ChangedPaths, path)
+		}
+	}
+	if newPR.Draft {
+		pr, err := s.repo.CreatePullRequest(ctx, newPR, repo.Assignment{})
+		if err != nil {
+			return api.PullRequest{}, nil, err
 		}
+		return pr, nil, nil
+	}
+
+	assignment, count, warnings, err := s.planAssignment(ctx, assignmentRequest{
+		PullRequestID:  newPR.ID,
+		Author:         author,
+		ReviewersCount: req.ReviewersCount,
+		IgnoreCapacity: req.IgnoreCapacity != nil && *req.IgnoreCapacity,
+		ChangedPaths:   newPR.ChangedPaths,
+		Labels:         newPR.Labels,
+	})
+	if err != nil {
+		return api.PullRequest{}, nil, err
+	}
+
+	pr, err := s.repo.CreatePullRequest(ctx, newPR, assignment)
+	if err != nil {
+		return api.PullRequest{}, nil, err
+	}
+
+	return pr, append(shortageWarnings(len(pr.AssignedReviewers), count), warnings...), nil
+}
+
+// MarkPullRequestReady takes a draft out of draft state and assigns its
+// reviewers the same way CreatePullRequest does for non-draft PRs. A draft
+// whose author has left their team becomes ready without reviewers, and one
+// that was converted back to draft keeps the reviewers it had. Marking a PR
+// that is already ready is a no-op.
+func (s *Service) MarkPullRequestReady(ctx context.Context, prID string, reviewersCount *int) (api.PullRequest, []api.Warning, error) {
+	if reviewersCount != nil {
+		if err := validateReviewersCount(*reviewersCount); err != nil {
+			return api.PullRequest{}, nil, err
+		}
+	}
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, nil, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, nil, err
+	}
+	if err := ensureOpen(pr, "mark ready"); err != nil {
+		return api.PullRequest{}, nil, err
+	}
+	if pr.Draft == nil || !*pr.Draft {
+		return pr, nil, nil
+	}
+	if len(pr.AssignedReviewers) > 0 {
+		pr, err = s.repo.MarkPullRequestReady(ctx, prID, repo.Assignment{})
+		if err != nil {
+			if err == repo.ErrNotFound {
+				return api.PullRequest{}, nil, NewError(api.NOTFOUND, "pull request not found")
+			}
+			return api.PullRequest{}, nil, err
+		}
+		return pr, nil, nil
+	}
+
+	author, err := s.repo.GetUser(ctx, pr.AuthorId)
+	if err != nil {
+		return api.PullRequest{}, nil, err
+	}
+
+	var assignment repo.Assignment
+	var count int
+	var warnings []api.Warning
+	if author.TeamName == "" {
+		settings, err := s.authorSettings(ctx, author)
+		if err != nil {
+			return api.PullRequest{}, nil, err
+		}
+		count = *settings.ReviewersCount
+		if reviewersCount != nil {
+			count = *reviewersCount
+		}
+	} else {
+		req := assignmentRequest{PullRequestID: prID, Author: author, ReviewersCount: reviewersCount}
+		if pr.ChangedPaths != nil {
+			req.ChangedPaths = *pr.ChangedPaths
+		}
+		if pr.Labels != nil {
+			req.Labels = *pr.Labels
+		}
+		assignment, count, warnings, err = s.planAssignment(ctx, req)
+		if err != nil {
+			return api.PullRequest{}, nil, err
+		}
+	}
+
+	pr, err = s.repo.MarkPullRequestReady(ctx, prID, assignment)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, nil, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, nil, err
+	}
+
+	return pr, append(shortageWarnings(len(pr.AssignedReviewers), count), warnings...), nil
+}
+
+// MarkPullRequestDraft puts an open PR back into draft state. Its reviewers
+// stay assigned and keep their decisions. Marking a draft is a no-op.
+func (s *Service) MarkPullRequestDraft(ctx context.Context, prID string) (api.PullRequest, error) {
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, err
+	}
+	if err := ensureOpen(pr, "mark draft"); err != nil {
 		return api.PullRequest{}, err
 	}
+	if pr.Draft != nil && *pr.Draft {
+		return pr, nil
+	}
 
-	users, err := s.repo.ListActiveUsersInTeam(ctx, author.TeamName)
+	pr, err = s.repo.MarkPullRequestDraft(ctx, prID)
 	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, NewError(api.NOTFOUND, "pull request not found")
+		}
 		return api.PullRequest{}, err
 	}
+	return pr, nil
+}
 
-	var candidates []string
+// assignmentRequest describes a pull request that needs reviewers.
+type assignmentRequest struct {
+	PullRequestID  string
+	Author         api.User
+	ReviewersCount *int
+	IgnoreCapacity bool
+	ChangedPaths   []string
+	Labels         []string
+}
+
+// planAssignment picks reviewers for a new PR by author among their active
+// teammates and returns the assignment together with the requested count.
+// Round-robin teams get a rotation that the repository advances atomically.
+func (s *Service) planAssignment(ctx context.Context, req assignmentRequest) (repo.Assignment, int, []api.Warning, error) {
+	author := req.Author
+	if author.TeamName == "" {
+		return repo.Assignment{}, 0, nil, NewError(api.NOTFOUND, "author is not a member of any team")
+	}
+
+	users, err := s.repo.ListActiveUsersInTeam(ctx, author.TeamName)
+	if err != nil {
+		return repo.Assignment{}, 0, nil, err
+	}
+
+	settings, err := s.teamSettings(ctx, author.TeamName)
+	if err != nil {
+		return repo.Assignment{}, 0, nil, err
+	}
+
+	var pool []api.User
 	for _, u := range users {
 		if u.UserId == author.UserId {
 			continue
 		}
-		candidates = append(candidates, u.UserId)
+		pool = append(pool, u)
+	}
+
+	candidates, err := s.eligibleReviewers(ctx, settings, pool, req.IgnoreCapacity)
+	if err != nil {
+		return repo.Assignment{}, 0, nil, err
+	}
+	eligible := len(candidates)
+
+	count := *settings.ReviewersCount
+	if req.ReviewersCount != nil {
+		count = *req.ReviewersCount
+	}
+
+	var assignment repo.Assignment
+	var warnings []api.Warning
+	exclude := map[string]struct{}{author.UserId: {}}
+	known := pool
+	if len(req.ChangedPaths) > 0 && count > 0 {
+		owner, owned, err := s.pickOwner(ctx, settings, req)
+		if err != nil {
+			return repo.Assignment{}, 0, nil, err
+		}
+		if owner.UserId != "" {
+			assignment.ReviewerIDs = []string{owner.UserId}
+			known = append(slices.Clip(pool), owner)
+		} else if owned {
+			warnings = append(warnings, api.Warning{
+				Code:    string(api.NOCANDIDATE),
+				Message: "no code owner of the changed paths is available to review",
+			})
+		}
+	}
+
+	mentors, policyWarnings, err := s.mentoringPicks(ctx, settings, known, assignment.ReviewerIDs, candidates, count, req)
+	if err != nil {
+		return repo.Assignment{}, 0, nil, err
+	}
+	assignment.ReviewerIDs = append(assignment.ReviewerIDs, mentors...)
+	warnings = append(warnings, policyWarnings...)
+	for _, id := range assignment.ReviewerIDs {
+		exclude[id] = struct{}{}
+	}
+	candidates = slices.DeleteFunc(candidates, func(id string) bool {
+		_, taken := exclude[id]
+		return taken
+	})
+
+	remaining := count - len(assignment.ReviewerIDs)
+	picked := len(assignment.ReviewerIDs) + min(remaining, len(candidates))
+	if settings.ReviewerStrategy == api.RoundRobin {
+		assignment.Rotation = &repo.Rotation{
+			TeamName: author.TeamName,
+			Next: func(cursor string) ([]string, string) {
+				return nextInRotation(candidates, cursor, remaining)
+			},
+		}
+	} else {
+		reviewers, err := s.selectReviewers(ctx, settings, candidates, remaining, req)
+		if err != nil {
+			return repo.Assignment{}, 0, nil, err
+		}
+		assignment.ReviewerIDs = append(assignment.ReviewerIDs, reviewers...)
+		picked = len(assignment.ReviewerIDs)
 	}
 
-	reviewers := pickRandom(s.rng, candidates, 2)
+	if picked < count {
+		assignment.FallbackIDs, err = s.fallbackReviewers(ctx, settings, exclude, count-picked, req)
+		if err != nil {
+			return repo.Assignment{}, 0, nil, err
+		}
+	}
 
-	pr, err := s.repo.CreatePullRequest(ctx, id, name, authorID, reviewers)
+	if count > 0 && len(pool) > 0 && eligible == 0 && len(assignment.ReviewerIDs) == 0 && len(assignment.FallbackIDs) == 0 {
+		return repo.Assignment{}, 0, nil, s.capacityError(ctx, author, len(pool))
+	}
+	return assignment, count, warnings, nil
+}
+
+// capacityError is the NO_CANDIDATE error for a team whose available
+// members are all at capacity. The pool already left out members on
+// leave, so they are only counted here to name them in the message.
+func (s *Service) capacityError(ctx context.Context, author api.User, available int) error {
+	team, err := s.repo.GetTeam(ctx, author.TeamName)
 	if err != nil {
-		return api.PullRequest{}, err
+		return err
 	}
-	return pr, nil
+	away := -available
+	for _, m := range team.Members {
+		if m.IsActive && m.UserId != author.UserId {
+			away++
+		}
+	}
+	if away > 0 {
+		return NewError(api.NOCANDIDATE, fmt.Sprintf(
+			"all candidates in team %s are unavailable or at their open review capacity (%d unavailable, %d at capacity)",
+			author.TeamName, away, available))
+	}
+	return NewError(api.NOCANDIDATE,
+		fmt.Sprintf("all candidates in team %s are at their open review capacity", author.TeamName))
 }
 
-func (s *Service) MergePullRequest(ctx context.Context, prID string) (api.PullRequest, error) {
+func shortageWarnings(assigned, required int) []api.Warning {
+	if assigned >= required {
+		return nil
+	}
+	return []api.Warning{{
+		Code:    string(api.NOCANDIDATE),
+		Message: fmt.Sprintf("assigned %d of %d reviewers, not enough active candidates in team", assigned, required),
+	}}
+}
+
+func (s *Service) MergePullRequest(ctx context.Context, prID string, force bool) (api.PullRequest, error) {
+	return s.mergePullRequest(ctx, prID, !force, force)
+}
+
+// mergePullRequest merges the PR, checking the team's merge policy first
+// when enforce is set. force is only recorded as the audit flag of an
+// admin overriding the policy.
+func (s *Service) mergePullRequest(ctx context.Context, prID string, enforce, force bool) (api.PullRequest, error) {
 	pr, err := s.repo.GetPullRequest(ctx, prID)
 	if err != nil {
 		if err == repo.ErrNotFound {
@@ -137,8 +741,51 @@ func (s *Service) MergePullRequest(ctx context.Context, prID string) (api.PullRe
 	if pr.Status == api.PullRequestStatusMERGED {
 		return pr, nil
 	}
+	if pr.Status == api.PullRequestStatusCLOSED {
+		return api.PullRequest{}, NewError(api.PRCLOSED, "cannot merge closed PR")
+	}
+
+	if enforce {
+		if err := s.checkMergePolicy(ctx, pr); err != nil {
+			return api.PullRequest{}, err
+		}
+	}
+
+	return s.repo.MarkPullRequestMerged(ctx, prID, force)
+}
+
+func (s *Service) checkMergePolicy(ctx context.Context, pr api.PullRequest) error {
+	author, err := s.repo.GetUser(ctx, pr.AuthorId)
+	if err != nil {
+		return err
+	}
+	settings, err := s.authorSettings(ctx, author)
+	if err != nil {
+		return err
+	}
+
+	approvals, changesRequested := 0, 0
+	if pr.Reviewers != nil {
+		for _, r := range *pr.Reviewers {
+			if r.Decision == nil {
+				continue
+			}
+			switch *r.Decision {
+			case api.APPROVED:
+				approvals++
+			case api.CHANGESREQUESTED:
+				changesRequested++
+			}
+		}
+	}
 
-	return s.repo.MarkPullRequestMerged(ctx, prID)
+	if *settings.BlockOnChangesRequested && changesRequested > 0 {
+		return NewError(api.NOTAPPROVED, fmt.Sprintf("%d reviewer(s) requested changes", changesRequested))
+	}
+	if required := *settings.RequiredApprovals; approvals < required {
+		return NewError(api.NOTAPPROVED, fmt.Sprintf("%d of %d required approvals", approvals, required))
+	}
+	return nil
 }
 
 func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string) (api.PullRequest, string, error) {
@@ -150,8 +797,8 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string)
 		return api.PullRequest{}, "", err
 	}
 
-	if pr.Status == api.PullRequestStatusMERGED {
-		return api.PullRequest{}, "", NewError(api.PRMERGED, "cannot reassign on merged PR")
+	if err := ensureOpen(pr, "reassign on"); err != nil {
+		return api.PullRequest{}, "", err
 	}
 
 	assigned := false
@@ -173,62 +820,290 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string)
 		return api.PullRequest{}, "", err
 	}
 
-	users, err := s.repo.ListActiveUsersInTeam(ctx, oldUser.TeamName)
+	newID, err := s.pickReplacement(ctx, pr, oldUser)
+	if err != nil {
+		return api.PullRequest{}, "", err
+	}
+	if newID == "" {
+		return api.PullRequest{}, "", NewError(api.NOCANDIDATE, "no active replacement candidate in team")
+	}
+
+	if err := s.repo.ReplaceReviewer(ctx, prID, oldUserID, newID); err != nil {
+		if err == repo.ErrReviewerNotFound {
+			return api.PullRequest{}, "", NewError(api.NOTASSIGNED, "reviewer is not assigned to this PR")
+		}
+		return api.PullRequest{}, "", err
+	}
+
+	updated, err := s.repo.GetPullRequest(ctx, prID)
 	if err != nil {
 		return api.PullRequest{}, "", err
 	}
 
+	return updated, newID, nil
+}
+
+// pickReplacement chooses someone to take over oldUser's slot on pr: an
+// active member of oldUser's team, or of the author's team when oldUser has
+// none, or else of that team's fallback teams unless the mentoring policy
+// requires a senior. It returns an empty id when nobody is eligible.
+func (s *Service) pickReplacement(ctx context.Context, pr api.PullRequest, oldUser api.User) (string, error) {
+	teamName := oldUser.TeamName
+	if teamName == "" {
+		author, err := s.repo.GetUser(ctx, pr.AuthorId)
+		if err != nil {
+			return "", err
+		}
+		if teamName = author.TeamName; teamName == "" {
+			return "", nil
+		}
+	}
+
+	users, err := s.repo.ListActiveUsersInTeam(ctx, teamName)
+	if err != nil {
+		return "", err
+	}
+
 	exclude := map[string]struct{}{
-		pr.AuthorId: {},
-		oldUserID:   {},
+		pr.AuthorId:    {},
+		oldUser.UserId: {},
 	}
 	for _, rid := range pr.AssignedReviewers {
 		exclude[rid] = struct{}{}
 	}
 
-	var candidates []string
+	var pool []api.User
 	for _, u := range users {
 		if _, skip := exclude[u.UserId]; skip {
 			continue
 		}
-		candidates = append(candidates, u.UserId)
+		pool = append(pool, u)
 	}
 
-	if len(candidates) == 0 {
-		return api.PullRequest{}, "", NewError(api.NOCANDIDATE, "no active replacement candidate in team")
+	settings, err := s.teamSettings(ctx, teamName)
+	if err != nil {
+		return "", err
 	}
 
-	newID := pickRandom(s.rng, candidates, 1)[0]
+	candidates, err := s.filterByCapacity(ctx, settings, pool)
+	if err != nil {
+		return "", err
+	}
+	level, strict, err := s.replacementLevel(ctx, settings, pr, oldUser)
+	if err != nil {
+		return "", err
+	}
+	if level != "" {
+		if matching := withLevel(pool, candidates, level); len(matching) > 0 || strict {
+			candidates = matching
+		}
+	}
 
-	if err := s.repo.ReplaceReviewer(ctx, prID, oldUserID, newID); err != nil {
-		if err == repo.ErrReviewerNotFound {
-			return api.PullRequest{}, "", NewError(api.NOTASSIGNED, "reviewer is not assigned to this PR")
+	req := assignmentRequest{PullRequestID: pr.PullRequestId, Author: api.User{UserId: pr.AuthorId}}
+	if pr.Labels != nil {
+		req.Labels = *pr.Labels
+	}
+	picked, err := s.selectReviewers(ctx, settings, candidates, 1, req)
+	if err != nil {
+		return "", err
+	}
+	if len(picked) == 0 && !strict {
+		if picked, err = s.fallbackReviewers(ctx, settings, exclude, 1, req); err != nil {
+			return "", err
 		}
-		return api.PullRequest{}, "", err
 	}
+	if len(picked) == 0 {
+		return "", nil
+	}
+	return picked[0], nil
+}
 
-	updated, err := s.repo.GetPullRequest(ctx, prID)
+func (s *Service) ClosePullRequest(ctx context.Context, prID string) (api.PullRequest, error) {
+	pr, err := s.repo.GetPullRequest(ctx, prID)
 	if err != nil {
-		return api.PullRequest{}, "", err
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, err
 	}
 
-	return updated, newID, nil
+	switch pr.Status {
+	case api.PullRequestStatusCLOSED:
+		return pr, nil
+	case api.PullRequestStatusMERGED:
+		return api.PullRequest{}, NewError(api.PRMERGED, "cannot close merged PR")
+	}
+
+	return s.repo.MarkPullRequestClosed(ctx, prID)
 }
 
-func (s *Service) ListUserReviewPRs(ctx context.Context, userID string) ([]api.PullRequestShort, error) {
-	return s.repo.ListUserReviewPRs(ctx, userID)
+// ReopenPullRequest moves a closed PR back to OPEN and replaces reviewers
+// that were deactivated while it was closed, all in one transaction.
+func (s *Service) ReopenPullRequest(ctx context.Context, prID string) (api.PullRequest, []api.Warning, error) {
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, nil, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, nil, err
+	}
+
+	switch pr.Status {
+	case api.PullRequestStatusOPEN:
+		return pr, nil, nil
+	case api.PullRequestStatusMERGED:
+		return api.PullRequest{}, nil, NewError(api.PRMERGED, "cannot reopen merged PR")
+	}
+
+	// Replacements are picked up front; the plan only keeps those that
+	// still hold once the reopen transaction sees the current state.
+	choices := map[string]string{}
+	var candidates []string
+	for _, rid := range pr.AssignedReviewers {
+		reviewer, err := s.repo.GetUser(ctx, rid)
+		if err != nil {
+			return api.PullRequest{}, nil, err
+		}
+		if reviewer.IsActive {
+			continue
+		}
+		newID, err := s.pickReplacement(ctx, pr, reviewer)
+		if err != nil {
+			return api.PullRequest{}, nil, err
+		}
+		if newID != "" {
+			choices[rid] = newID
+			candidates = append(candidates, newID)
+			pr.AssignedReviewers = append(pr.AssignedReviewers, newID)
+		}
+	}
+
+	pr, slots, err := s.repo.ReopenPullRequest(ctx, prID, candidates, func(active []string, slots []repo.ReviewSlot) {
+		for i := range slots {
+			newID, ok := choices[slots[i].OldReviewerID]
+			if ok && slices.Contains(active, newID) && !slices.Contains(slots[i].ReviewerIDs, newID) {
+				slots[i].NewReviewerID = newID
+			}
+		}
+	})
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, nil, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, nil, err
+	}
+
+	var warnings []api.Warning
+	for _, slot := range slots {
+		if slot.NewReviewerID == "" {
+			warnings = append(warnings, api.Warning{
+				Code:    string(api.NOCANDIDATE),
+				Message: fmt.Sprintf("reviewer %s is inactive and has no active replacement in team", slot.OldReviewerID),
+			})
+		}
+	}
+	return pr, warnings, nil
 }
 
-func pickRandom(rng *rand.Rand, items []string, max int) []string {
-	if len(items) <= max {
-		out := make([]string, len(items))
-		copy(out, items)
-		return out
+func ensureOpen(pr api.PullRequest, action string) error {
+	switch pr.Status {
+	case api.PullRequestStatusMERGED:
+		return NewError(api.PRMERGED, fmt.Sprintf("cannot %s merged PR", action))
+	case api.PullRequestStatusCLOSED:
+		return NewError(api.PRCLOSED, fmt.Sprintf("cannot %s closed PR", action))
 	}
-	out := make([]string, max)
-	perm := rng.Perm(len(items))
-	for i := 0; i < max; i++ {
-		out[i] = items[perm[i]]
+	return nil
+}
+
+func (s *Service) SubmitReview(ctx context.Context, prID, reviewerID string, decision api.ReviewDecision) (api.PullRequest, error) {
+	switch decision {
+	case api.APPROVED, api.CHANGESREQUESTED, api.COMMENTED:
+	default:
+		return api.PullRequest{}, NewError(api.BADREQUEST, fmt.Sprintf("unknown review decision %q", decision))
+	}
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.PullRequest{}, NewError(api.NOTFOUND, "pull request not found")
+		}
+		return api.PullRequest{}, err
+	}
+
+	if err := ensureOpen(pr, "review"); err != nil {
+		return api.PullRequest{}, err
+	}
+
+	if err := s.repo.SetReviewDecision(ctx, prID, reviewerID, decision); err != nil {
+		if err == repo.ErrReviewerNotFound {
+			return api.PullRequest{}, NewError(api.NOTASSIGNED, "reviewer is not assigned to this PR")
+		}
+		return api.PullRequest{}, err
+	}
+
+	return s.repo.GetPullRequest(ctx, prID)
+}
+
+func (s *Service) ListUserReviewPRs(ctx context.Context, userID string, pendingOnly bool) ([]api.PullRequestShort, error) {
+	return s.repo.ListUserReviewPRs(ctx, userID, pendingOnly)
+}
+
+// selectReviewers picks up to count of candidates for the pull request
+// described by pr using the team's strategy.
+func (s *Service) selectReviewers(ctx context.Context, settings api.TeamSettings, candidates []string, count int, pr assignmentRequest) ([]string, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	req := SelectionRequest{
+		Candidates: candidates,
+		Count:      count,
+		Weights:    *settings.ReviewerWeights,
+	}
+
+	var err error
+	switch settings.ReviewerStrategy {
+	case api.LeastLoaded:
+		if len(candidates) > count {
+			req.OpenReviews, err = s.repo.CountOpenReviews(ctx, candidates)
+		}
+	case api.RoundRobin:
+		req.Cursor, err = s.repo.GetRotationCursor(ctx, settings.TeamName)
+	case api.SkillMatch:
+		if len(candidates) > count && len(pr.Labels) > 0 {
+			req.Overlap, err = s.skillOverlap(ctx, candidates, pr.Labels)
+		}
+	case api.Diverse:
+		if len(candidates) > count && pr.Author.UserId != "" {
+			req.RecentReviews, err = s.repo.CountRecentReviews(ctx, pr.Author.UserId, *settings.DiversityWindow)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.selectors[settings.ReviewerStrategy].Select(s.rngFor(pr.PullRequestID), req), nil
+}
+
+// nextInRotation picks the next count candidates after cursor and
+// returns them with the cursor to persist.
+func nextInRotation(candidates []string, cursor string, count int) ([]string, string) {
+	picked := rotate(candidates, cursor, count)
+	if len(picked) == 0 {
+		return picked, cursor
+	}
+	return picked, picked[len(picked)-1]
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
 	}
 	return out
 }
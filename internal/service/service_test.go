@@ -2,40 +2,157 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/github"
+	"pr-reviewer/internal/notify"
 	"pr-reviewer/internal/repo"
 )
 
 type mockRepo struct {
-	createTeamWithMembers func(context.Context, api.Team) (api.Team, error)
+	createTeamWithMembers func(context.Context, api.Team, bool, map[string]repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error)
 	getTeam               func(context.Context, string) (api.Team, error)
+	addTeamMembers        func(context.Context, string, []api.TeamMember, repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error)
+	removeTeamMembers     func(context.Context, string, []string, repo.HandoffPlan) (api.Team, []api.User, []repo.ReviewSlot, error)
+	deleteTeam            func(context.Context, string) ([]api.User, error)
+	moveUserToTeam        func(context.Context, string, string, repo.HandoffPlan) (api.User, string, []repo.ReviewSlot, error)
+	listTeamMoves         func(context.Context, string) ([]api.TeamMove, error)
+	addUnavailability     func(context.Context, api.UnavailabilityWindow, repo.HandoffPlan) (api.UnavailabilityWindow, []repo.ReviewSlot, error)
+	listUnavailability    func(context.Context, string) ([]api.UnavailabilityWindow, error)
+	deleteUnavailability  func(context.Context, int64) (api.UnavailabilityWindow, error)
+	listDueHandoffs       func(context.Context, int) ([]repo.DueHandoff, error)
+	handOffDueWindow      func(context.Context, int64, string, repo.HandoffPlan) ([]repo.ReviewSlot, error)
 	setUserActive         func(context.Context, string, bool) (api.User, error)
+	setUserMaxOpenReviews func(context.Context, string, *int) (api.User, error)
+	setUserTags           func(context.Context, string, []string) (api.User, error)
+	setUserLevel          func(context.Context, string, api.UserLevel) (api.User, error)
+	getCodeOwners         func(context.Context, string) (api.CodeOwners, error)
+	setCodeOwners         func(context.Context, string, string) (api.CodeOwners, error)
+	deactivateUser        func(context.Context, string, repo.HandoffPlan) (api.User, []repo.ReviewSlot, error)
+	deactivateUsers       func(context.Context, string, []string, bool, repo.HandoffPlan) ([]api.User, []repo.ReviewSlot, error)
 	getUser               func(context.Context, string) (api.User, error)
 	listActiveUsersInTeam func(context.Context, string) ([]api.User, error)
+	listActiveUsers       func(context.Context, []string) ([]api.User, error)
+	countOpenReviews      func(context.Context, []string) (map[string]int, error)
+	countRecentReviews    func(context.Context, string, int) (map[string]int, error)
+	getTeamSettings       func(context.Context, string) (api.TeamSettings, error)
+	upsertTeamSettings    func(context.Context, api.TeamSettings) (api.TeamSettings, error)
+	getRotationCursor     func(context.Context, string) (string, error)
 	pullRequestExists     func(context.Context, string) (bool, error)
-	createPullRequest     func(context.Context, string, string, string, []string) (api.PullRequest, error)
+	createPullRequest     func(context.Context, repo.NewPullRequest, repo.Assignment) (api.PullRequest, error)
+	markPullRequestReady  func(context.Context, string, repo.Assignment) (api.PullRequest, error)
+	markPullRequestDraft  func(context.Context, string) (api.PullRequest, error)
 	getPullRequest        func(context.Context, string) (api.PullRequest, error)
-	markPullRequestMerged func(context.Context, string) (api.PullRequest, error)
+	markPullRequestMerged func(context.Context, string, bool) (api.PullRequest, error)
+	markPullRequestClosed func(context.Context, string) (api.PullRequest, error)
+	reopenPullRequest     func(context.Context, string, []string, repo.HandoffPlan) (api.PullRequest, []repo.ReviewSlot, error)
 	replaceReviewer       func(context.Context, string, string, string) error
-	listUserReviewPRs     func(context.Context, string) ([]api.PullRequestShort, error)
+	setReviewDecision     func(context.Context, string, string, api.ReviewDecision) error
+	listUserReviewPRs     func(context.Context, string, bool) ([]api.PullRequestShort, error)
+	setExternalLogin      func(context.Context, api.ExternalLogin) (api.ExternalLogin, error)
+	deleteExternalLogin   func(context.Context, api.GitProvider, string) (api.ExternalLogin, error)
+	listExternalLogins    func(context.Context, api.GitProvider) ([]api.ExternalLogin, error)
+	externalLoginUser     func(context.Context, api.GitProvider, string) (string, error)
+	externalLoginsOf      func(context.Context, api.GitProvider, []string) (map[string]string, error)
+	claimReviewerSyncJobs func(context.Context, int, time.Duration) ([]repo.ReviewerSyncJob, error)
+	completeReviewerSync  func(context.Context, int64) error
+	retryReviewerSync     func(context.Context, int64, time.Duration, string) error
+	failReviewerSync      func(context.Context, int64, string) error
+	createSubscription    func(context.Context, repo.NewWebhookSubscription) (api.WebhookSubscription, error)
+	listSubscriptions     func(context.Context) ([]api.WebhookSubscription, error)
+	deleteSubscription    func(context.Context, int64) (api.WebhookSubscription, error)
+	dispatchOutbox        func(context.Context, int, func(repo.OutboxMessage) (repo.OutboxRoute, error)) (int, error)
+	claimWebhooks         func(context.Context, int, time.Duration) ([]repo.WebhookDeliveryJob, error)
+	completeWebhook       func(context.Context, int64) error
+	retryWebhook          func(context.Context, int64, time.Duration, string) error
+	deadLetterWebhook     func(context.Context, int64, string) error
+	listWebhookDeliveries func(context.Context, int64, api.WebhookDeliveryStatus) ([]api.WebhookDelivery, error)
+	redeliverWebhook      func(context.Context, int64) (api.WebhookDelivery, error)
 }
 
-func (m *mockRepo) CreateTeamWithMembers(ctx context.Context, team api.Team) (api.Team, error) {
-	return m.createTeamWithMembers(ctx, team)
+func (m *mockRepo) CreateTeamWithMembers(ctx context.Context, team api.Team, moveMembers bool, plans map[string]repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error) {
+	return m.createTeamWithMembers(ctx, team, moveMembers, plans)
 }
 
 func (m *mockRepo) GetTeam(ctx context.Context, name string) (api.Team, error) {
 	return m.getTeam(ctx, name)
 }
 
+func (m *mockRepo) AddTeamMembers(ctx context.Context, name string, members []api.TeamMember, plan repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error) {
+	return m.addTeamMembers(ctx, name, members, plan)
+}
+
+func (m *mockRepo) RemoveTeamMembers(ctx context.Context, name string, ids []string, plan repo.HandoffPlan) (api.Team, []api.User, []repo.ReviewSlot, error) {
+	return m.removeTeamMembers(ctx, name, ids, plan)
+}
+
+func (m *mockRepo) DeleteTeam(ctx context.Context, name string) ([]api.User, error) {
+	return m.deleteTeam(ctx, name)
+}
+
+func (m *mockRepo) MoveUserToTeam(ctx context.Context, userID, team string, plan repo.HandoffPlan) (api.User, string, []repo.ReviewSlot, error) {
+	return m.moveUserToTeam(ctx, userID, team, plan)
+}
+
+func (m *mockRepo) ListTeamMoves(ctx context.Context, userID string) ([]api.TeamMove, error) {
+	return m.listTeamMoves(ctx, userID)
+}
+
+func (m *mockRepo) AddUnavailability(ctx context.Context, window api.UnavailabilityWindow, plan repo.HandoffPlan) (api.UnavailabilityWindow, []repo.ReviewSlot, error) {
+	return m.addUnavailability(ctx, window, plan)
+}
+
+func (m *mockRepo) ListUnavailability(ctx context.Context, userID string) ([]api.UnavailabilityWindow, error) {
+	return m.listUnavailability(ctx, userID)
+}
+
+func (m *mockRepo) DeleteUnavailability(ctx context.Context, id int64) (api.UnavailabilityWindow, error) {
+	return m.deleteUnavailability(ctx, id)
+}
+
+func (m *mockRepo) ListDueHandoffs(ctx context.Context, limit int) ([]repo.DueHandoff, error) {
+	return m.listDueHandoffs(ctx, limit)
+}
+
+func (m *mockRepo) HandOffDueWindow(ctx context.Context, windowID int64, teamName string, plan repo.HandoffPlan) ([]repo.ReviewSlot, error) {
+	return m.handOffDueWindow(ctx, windowID, teamName, plan)
+}
+
 func (m *mockRepo) SetUserActive(ctx context.Context, id string, active bool) (api.User, error) {
 	return m.setUserActive(ctx, id, active)
 }
 
+func (m *mockRepo) SetUserMaxOpenReviews(ctx context.Context, id string, limit *int) (api.User, error) {
+	return m.setUserMaxOpenReviews(ctx, id, limit)
+}
+
+func (m *mockRepo) SetUserTags(ctx context.Context, id string, tags []string) (api.User, error) {
+	return m.setUserTags(ctx, id, tags)
+}
+
+func (m *mockRepo) SetUserLevel(ctx context.Context, id string, level api.UserLevel) (api.User, error) {
+	return m.setUserLevel(ctx, id, level)
+}
+
+func (m *mockRepo) DeactivateUser(ctx context.Context, id string, plan repo.HandoffPlan) (api.User, []repo.ReviewSlot, error) {
+	return m.deactivateUser(ctx, id, plan)
+}
+
+func (m *mockRepo) DeactivateUsers(ctx context.Context, team string, ids []string, allExcept bool, plan repo.HandoffPlan) ([]api.User, []repo.ReviewSlot, error) {
+	return m.deactivateUsers(ctx, team, ids, allExcept, plan)
+}
+
 func (m *mockRepo) GetUser(ctx context.Context, id string) (api.User, error) {
 	return m.getUser(ctx, id)
 }
@@ -44,28 +161,156 @@ func (m *mockRepo) ListActiveUsersInTeam(ctx context.Context, team string) ([]ap
 	return m.listActiveUsersInTeam(ctx, team)
 }
 
+func (m *mockRepo) ListActiveUsers(ctx context.Context, userIDs []string) ([]api.User, error) {
+	return m.listActiveUsers(ctx, userIDs)
+}
+
+func (m *mockRepo) GetCodeOwners(ctx context.Context, team string) (api.CodeOwners, error) {
+	return m.getCodeOwners(ctx, team)
+}
+
+func (m *mockRepo) SetCodeOwners(ctx context.Context, team, content string) (api.CodeOwners, error) {
+	return m.setCodeOwners(ctx, team, content)
+}
+
+func (m *mockRepo) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return m.countOpenReviews(ctx, userIDs)
+}
+
+func (m *mockRepo) CountRecentReviews(ctx context.Context, authorID string, n int) (map[string]int, error) {
+	return m.countRecentReviews(ctx, authorID, n)
+}
+
+func (m *mockRepo) GetTeamSettings(ctx context.Context, team string) (api.TeamSettings, error) {
+	return m.getTeamSettings(ctx, team)
+}
+
+func (m *mockRepo) UpsertTeamSettings(ctx context.Context, settings api.TeamSettings) (api.TeamSettings, error) {
+	return m.upsertTeamSettings(ctx, settings)
+}
+
 func (m *mockRepo) PullRequestExists(ctx context.Context, id string) (bool, error) {
 	return m.pullRequestExists(ctx, id)
 }
 
-func (m *mockRepo) CreatePullRequest(ctx context.Context, id, name, authorID string, reviewers []string) (api.PullRequest, error) {
-	return m.createPullRequest(ctx, id, name, authorID, reviewers)
+func (m *mockRepo) CreatePullRequest(ctx context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+	return m.createPullRequest(ctx, pr, assignment)
+}
+
+func (m *mockRepo) MarkPullRequestReady(ctx context.Context, id string, assignment repo.Assignment) (api.PullRequest, error) {
+	return m.markPullRequestReady(ctx, id, assignment)
+}
+
+func (m *mockRepo) MarkPullRequestDraft(ctx context.Context, id string) (api.PullRequest, error) {
+	return m.markPullRequestDraft(ctx, id)
 }
 
 func (m *mockRepo) GetPullRequest(ctx context.Context, id string) (api.PullRequest, error) {
 	return m.getPullRequest(ctx, id)
 }
 
-func (m *mockRepo) MarkPullRequestMerged(ctx context.Context, id string) (api.PullRequest, error) {
-	return m.markPullRequestMerged(ctx, id)
+func (m *mockRepo) MarkPullRequestMerged(ctx context.Context, id string, force bool) (api.PullRequest, error) {
+	return m.markPullRequestMerged(ctx, id, force)
+}
+
+func (m *mockRepo) MarkPullRequestClosed(ctx context.Context, id string) (api.PullRequest, error) {
+	return m.markPullRequestClosed(ctx, id)
+}
+
+func (m *mockRepo) ReopenPullRequest(ctx context.Context, id string, candidates []string, plan repo.HandoffPlan) (api.PullRequest, []repo.ReviewSlot, error) {
+	return m.reopenPullRequest(ctx, id, candidates, plan)
 }
 
 func (m *mockRepo) ReplaceReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
 	return m.replaceReviewer(ctx, prID, oldUserID, newUserID)
 }
 
-func (m *mockRepo) ListUserReviewPRs(ctx context.Context, userID string) ([]api.PullRequestShort, error) {
-	return m.listUserReviewPRs(ctx, userID)
+func (m *mockRepo) SetReviewDecision(ctx context.Context, prID, reviewerID string, decision api.ReviewDecision) error {
+	return m.setReviewDecision(ctx, prID, reviewerID, decision)
+}
+
+func (m *mockRepo) ListUserReviewPRs(ctx context.Context, userID string, pendingOnly bool) ([]api.PullRequestShort, error) {
+	return m.listUserReviewPRs(ctx, userID, pendingOnly)
+}
+
+func (m *mockRepo) GetRotationCursor(ctx context.Context, team string) (string, error) {
+	return m.getRotationCursor(ctx, team)
+}
+
+func (m *mockRepo) SetExternalLogin(ctx context.Context, login api.ExternalLogin) (api.ExternalLogin, error) {
+	return m.setExternalLogin(ctx, login)
+}
+
+func (m *mockRepo) DeleteExternalLogin(ctx context.Context, provider api.GitProvider, login string) (api.ExternalLogin, error) {
+	return m.deleteExternalLogin(ctx, provider, login)
+}
+
+func (m *mockRepo) ListExternalLogins(ctx context.Context, provider api.GitProvider) ([]api.ExternalLogin, error) {
+	return m.listExternalLogins(ctx, provider)
+}
+
+func (m *mockRepo) ExternalLoginUser(ctx context.Context, provider api.GitProvider, login string) (string, error) {
+	return m.externalLoginUser(ctx, provider, login)
+}
+
+func (m *mockRepo) ExternalLoginsOf(ctx context.Context, provider api.GitProvider, userIDs []string) (map[string]string, error) {
+	return m.externalLoginsOf(ctx, provider, userIDs)
+}
+
+func (m *mockRepo) ClaimReviewerSyncJobs(ctx context.Context, limit int, lease time.Duration) ([]repo.ReviewerSyncJob, error) {
+	return m.claimReviewerSyncJobs(ctx, limit, lease)
+}
+
+func (m *mockRepo) CompleteReviewerSyncJob(ctx context.Context, id int64) error {
+	return m.completeReviewerSync(ctx, id)
+}
+
+func (m *mockRepo) RetryReviewerSyncJob(ctx context.Context, id int64, delay time.Duration, lastErr string) error {
+	return m.retryReviewerSync(ctx, id, delay, lastErr)
+}
+
+func (m *mockRepo) FailReviewerSyncJob(ctx context.Context, id int64, lastErr string) error {
+	return m.failReviewerSync(ctx, id, lastErr)
+}
+
+func (m *mockRepo) CreateWebhookSubscription(ctx context.Context, sub repo.NewWebhookSubscription) (api.WebhookSubscription, error) {
+	return m.createSubscription(ctx, sub)
+}
+
+func (m *mockRepo) ListWebhookSubscriptions(ctx context.Context) ([]api.WebhookSubscription, error) {
+	return m.listSubscriptions(ctx)
+}
+
+func (m *mockRepo) DeleteWebhookSubscription(ctx context.Context, id int64) (api.WebhookSubscription, error) {
+	return m.deleteSubscription(ctx, id)
+}
+
+func (m *mockRepo) DispatchOutbox(ctx context.Context, limit int, route func(repo.OutboxMessage) (repo.OutboxRoute, error)) (int, error) {
+	return m.dispatchOutbox(ctx, limit, route)
+}
+
+func (m *mockRepo) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repo.WebhookDeliveryJob, error) {
+	return m.claimWebhooks(ctx, limit, lease)
+}
+
+func (m *mockRepo) CompleteWebhookDelivery(ctx context.Context, id int64) error {
+	return m.completeWebhook(ctx, id)
+}
+
+func (m *mockRepo) RetryWebhookDelivery(ctx context.Context, id int64, delay time.Duration, lastErr string) error {
+	return m.retryWebhook(ctx, id, delay, lastErr)
+}
+
+func (m *mockRepo) DeadLetterWebhookDelivery(ctx context.Context, id int64, lastErr string) error {
+	return m.deadLetterWebhook(ctx, id, lastErr)
+}
+
+func (m *mockRepo) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status api.WebhookDeliveryStatus) ([]api.WebhookDelivery, error) {
+	return m.listWebhookDeliveries(ctx, subscriptionID, status)
+}
+
+func (m *mockRepo) RedeliverWebhookDelivery(ctx context.Context, id int64) (api.WebhookDelivery, error) {
+	return m.redeliverWebhook(ctx, id)
 }
 
 func TestService_CreatePullRequest_PRExists(t *testing.T) {
@@ -75,7 +320,7 @@ func TestService_CreatePullRequest_PRExists(t *testing.T) {
 		},
 	})
 
-	_, err := svc.CreatePullRequest(context.Background(), "pr-1", "Add feature", "author-1")
+	_, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author-1"))
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
@@ -98,10 +343,256 @@ func TestService_CreatePullRequest_AuthorNotFound(t *testing.T) {
 		},
 	})
 
-	_, err := svc.CreatePullRequest(context.Background(), "pr-2", "Add", "missing-author")
+	_, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-2", "missing-author"))
 	assertServiceErrorCode(t, err, api.NOTFOUND)
 }
 
+func TestService_CreatePullRequest_PrefersLeastLoaded(t *testing.T) {
+	var got []string
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "author", TeamName: "team", IsActive: true},
+				{UserId: "u1", TeamName: "team", IsActive: true},
+				{UserId: "u2", TeamName: "team", IsActive: true},
+				{UserId: "u3", TeamName: "team", IsActive: true},
+			}, nil
+		},
+		countOpenReviews: func(context.Context, []string) (map[string]int, error) {
+			return map[string]int{"u1": 3, "u3": 1}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AuthorId: pr.AuthorID, AssignedReviewers: got}, nil
+		},
+	})
+
+	if _, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "u2" || got[1] != "u3" {
+		t.Fatalf("expected reviewers [u2 u3], got %v", got)
+	}
+}
+
+func TestService_CreatePullRequest_WarnsOnShortage(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "author", TeamName: "team", IsActive: true},
+				{UserId: "u1", TeamName: "team", IsActive: true},
+				{UserId: "u2", TeamName: "team", IsActive: true},
+			}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			return api.PullRequest{PullRequestId: pr.ID, AuthorId: pr.AuthorID, AssignedReviewers: assignment.ReviewerIDs}, nil
+		},
+	})
+
+	req := createRequest("pr-1", "author")
+	count := 3
+	req.ReviewersCount = &count
+
+	pr, warnings, err := svc.CreatePullRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", pr.AssignedReviewers)
+	}
+	if len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected NO_CANDIDATE warning, got %+v", warnings)
+	}
+}
+
+func TestService_CreatePullRequest_InvalidReviewersCount(t *testing.T) {
+	svc := newTestService(&mockRepo{})
+
+	req := createRequest("pr-1", "author")
+	count := -1
+	req.ReviewersCount = &count
+
+	_, _, err := svc.CreatePullRequest(context.Background(), req)
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestLeastLoadedSelector_BreaksTiesRandomly(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	req := SelectionRequest{
+		Candidates:  []string{"u1", "u2", "u3", "u4"},
+		Count:       1,
+		OpenReviews: map[string]int{"u1": 2, "u4": 2},
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		picked := LeastLoadedSelector{}.Select(rng, req)
+		if len(picked) != 1 {
+			t.Fatalf("expected 1 reviewer, got %v", picked)
+		}
+		seen[picked[0]] = true
+	}
+	if seen["u1"] || seen["u4"] {
+		t.Fatalf("loaded candidates must not be picked: %v", seen)
+	}
+	if !seen["u2"] || !seen["u3"] {
+		t.Fatalf("expected both idle candidates to be picked over time: %v", seen)
+	}
+}
+
+func TestService_CreatePullRequest_RoundRobinAdvancesCursor(t *testing.T) {
+	cursor := ""
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "u2", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "u3", TeamName: "team", IsActive: true},
+				{UserId: "u1", TeamName: "team", IsActive: true},
+				{UserId: "u2", TeamName: "team", IsActive: true},
+				{UserId: "u4", TeamName: "team", IsActive: true},
+			}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.RoundRobin}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			var reviewers []string
+			reviewers, cursor = assignment.Rotation.Next(cursor)
+			return api.PullRequest{PullRequestId: pr.ID, AuthorId: pr.AuthorID, AssignedReviewers: reviewers}, nil
+		},
+	})
+
+	want := [][]string{{"u1", "u3"}, {"u4", "u1"}, {"u3", "u4"}}
+	for i, w := range want {
+		pr, _, err := svc.CreatePullRequest(context.Background(), createRequest(fmt.Sprintf("pr-%d", i), "u2"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := pr.AssignedReviewers
+		if len(got) != 2 || got[0] != w[0] || got[1] != w[1] {
+			t.Fatalf("round %d: expected %v, got %v", i, w, got)
+		}
+	}
+	if cursor != "u4" {
+		t.Fatalf("expected cursor u4, got %q", cursor)
+	}
+}
+
+func TestWeightedSelector_SkipsZeroWeight(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	req := SelectionRequest{
+		Candidates: []string{"u1", "u2", "u3"},
+		Count:      2,
+		Weights:    map[string]int{"u1": 0, "u2": 5},
+	}
+
+	for i := 0; i < 50; i++ {
+		picked := WeightedSelector{}.Select(rng, req)
+		if len(picked) != 2 {
+			t.Fatalf("expected 2 reviewers, got %v", picked)
+		}
+		for _, id := range picked {
+			if id == "u1" {
+				t.Fatalf("zero-weight candidate picked: %v", picked)
+			}
+		}
+	}
+}
+
+func TestService_SetTeamSettings_UnknownStrategy(t *testing.T) {
+	svc := newTestService(&mockRepo{})
+
+	_, err := svc.SetTeamSettings(context.Background(), api.TeamSettings{
+		TeamName:         "team",
+		ReviewerStrategy: "fastest",
+	}, false)
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_SetTeamSettings_KeepsStoredFields(t *testing.T) {
+	required, block, window := 2, true, 10
+	var saved api.TeamSettings
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{
+				TeamName:                team,
+				ReviewerStrategy:        api.Random,
+				ReviewerWeights:         &map[string]int{},
+				RequiredApprovals:       &required,
+				BlockOnChangesRequested: &block,
+				FallbackTeams:           &[]string{"platform"},
+				DiversityWindow:         &window,
+			}, nil
+		},
+		upsertTeamSettings: func(_ context.Context, settings api.TeamSettings) (api.TeamSettings, error) {
+			saved = settings
+			return settings, nil
+		},
+	})
+
+	if _, err := svc.SetTeamSettings(context.Background(), api.TeamSettings{TeamName: "team", ReviewerStrategy: api.LeastLoaded}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.ReviewerStrategy != api.LeastLoaded || *saved.RequiredApprovals != 2 || !*saved.BlockOnChangesRequested {
+		t.Fatalf("expected the merge gate to be kept, got %+v", saved)
+	}
+	if len(*saved.FallbackTeams) != 1 || *saved.DiversityWindow != 10 || *saved.ReviewersCount != defaultReviewersCount || *saved.RequireSenior {
+		t.Fatalf("expected stored values and defaults for omitted fields, got %+v", saved)
+	}
+}
+
+func TestService_SetTeamSettings_KeepsCapacityLimit(t *testing.T) {
+	limit := 3
+	var saved api.TeamSettings
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random, MaxOpenReviews: &limit}, nil
+		},
+		upsertTeamSettings: func(_ context.Context, settings api.TeamSettings) (api.TeamSettings, error) {
+			saved = settings
+			return settings, nil
+		},
+	})
+	update := api.TeamSettings{TeamName: "team", ReviewerStrategy: api.RoundRobin}
+
+	if _, err := svc.SetTeamSettings(context.Background(), update, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.ReviewerStrategy != api.RoundRobin || saved.MaxOpenReviews == nil || *saved.MaxOpenReviews != 3 {
+		t.Fatalf("expected the strategy to change and the limit to stay 3, got %+v", saved)
+	}
+
+	if _, err := svc.SetTeamSettings(context.Background(), update, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.MaxOpenReviews != nil {
+		t.Fatalf("expected an explicit null to clear the limit, got %d", *saved.MaxOpenReviews)
+	}
+}
+
 func TestService_ReassignReviewer_PRMerged(t *testing.T) {
 	svc := newTestService(&mockRepo{
 		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
@@ -152,9 +643,1559 @@ func TestService_ReassignReviewer_NoCandidate(t *testing.T) {
 				{UserId: "u1", TeamName: "team", IsActive: true},
 			}, nil
 		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team}, nil
+		},
+	})
+
+	_, _, err := svc.ReassignReviewer(context.Background(), "pr-1", "u1")
+	assertServiceErrorCode(t, err, api.NOCANDIDATE)
+}
+
+func createRequest(id, authorID string) api.PostPullRequestCreateJSONBody {
+	return api.PostPullRequestCreateJSONBody{
+		PullRequestId:   id,
+		PullRequestName: "Add feature",
+		AuthorId:        authorID,
+	}
+}
+
+func TestService_SubmitReview_NotAssigned(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+			return api.PullRequest{
+				PullRequestId:     "pr-1",
+				AuthorId:          "author",
+				Status:            api.PullRequestStatusOPEN,
+				AssignedReviewers: []string{"u2"},
+			}, nil
+		},
+		setReviewDecision: func(context.Context, string, string, api.ReviewDecision) error {
+			return repo.ErrReviewerNotFound
+		},
+	})
+
+	_, err := svc.SubmitReview(context.Background(), "pr-1", "u1", api.APPROVED)
+	assertServiceErrorCode(t, err, api.NOTASSIGNED)
+}
+
+func TestService_SubmitReview_UnknownDecision(t *testing.T) {
+	svc := newTestService(&mockRepo{})
+
+	_, err := svc.SubmitReview(context.Background(), "pr-1", "u1", "LGTM")
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_MergePullRequest_RequiresApprovals(t *testing.T) {
+	approved := api.APPROVED
+	changes := api.CHANGESREQUESTED
+	required := 2
+	block := true
+
+	var forced []bool
+	newSvc := func(reviewers []api.Reviewer) *Service {
+		return newTestService(&mockRepo{
+			getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+				return api.PullRequest{
+					PullRequestId: "pr-1",
+					AuthorId:      "author",
+					Status:        api.PullRequestStatusOPEN,
+					Reviewers:     &reviewers,
+				}, nil
+			},
+			getUser: func(context.Context, string) (api.User, error) {
+				return api.User{UserId: "author", TeamName: "team"}, nil
+			},
+			getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+				return api.TeamSettings{
+					TeamName:                team,
+					RequiredApprovals:       &required,
+					BlockOnChangesRequested: &block,
+				}, nil
+			},
+			markPullRequestMerged: func(_ context.Context, id string, force bool) (api.PullRequest, error) {
+				forced = append(forced, force)
+				return api.PullRequest{PullRequestId: id, Status: api.PullRequestStatusMERGED, ForceMerged: &force}, nil
+			},
+		})
+	}
+
+	_, err := newSvc([]api.Reviewer{
+		{UserId: "u1", Decision: &approved},
+		{UserId: "u2"},
+	}).MergePullRequest(context.Background(), "pr-1", false)
+	assertServiceErrorCode(t, err, api.NOTAPPROVED)
+
+	_, err = newSvc([]api.Reviewer{
+		{UserId: "u1", Decision: &approved},
+		{UserId: "u2", Decision: &approved},
+		{UserId: "u3", Decision: &changes},
+	}).MergePullRequest(context.Background(), "pr-1", false)
+	assertServiceErrorCode(t, err, api.NOTAPPROVED)
+
+	if _, err := newSvc([]api.Reviewer{
+		{UserId: "u1", Decision: &approved},
+		{UserId: "u2", Decision: &approved},
+	}).MergePullRequest(context.Background(), "pr-1", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := newSvc(nil).MergePullRequest(context.Background(), "pr-1", true); err != nil {
+		t.Fatalf("unexpected error on forced merge: %v", err)
+	}
+
+	if len(forced) != 2 || forced[0] || !forced[1] {
+		t.Fatalf("expected merges recorded as [false true], got %v", forced)
+	}
+}
+
+func TestService_ReassignReviewer_PRClosed(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+			return api.PullRequest{
+				PullRequestId:     "pr-1",
+				AuthorId:          "author",
+				Status:            api.PullRequestStatusCLOSED,
+				AssignedReviewers: []string{"u1"},
+			}, nil
+		},
 	})
 
 	_, _, err := svc.ReassignReviewer(context.Background(), "pr-1", "u1")
+	assertServiceErrorCode(t, err, api.PRCLOSED)
+}
+
+func TestService_ReopenPullRequest_ReplacesInactiveReviewers(t *testing.T) {
+	pr := api.PullRequest{
+		PullRequestId:     "pr-1",
+		AuthorId:          "author",
+		Status:            api.PullRequestStatusCLOSED,
+		AssignedReviewers: []string{"u1", "u2", "u4"},
+	}
+
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+			return pr, nil
+		},
+		reopenPullRequest: func(_ context.Context, _ string, candidates []string, plan repo.HandoffPlan) (api.PullRequest, []repo.ReviewSlot, error) {
+			if !reflect.DeepEqual(candidates, []string{"u3", "u5"}) {
+				t.Fatalf("expected candidates [u3 u5], got %v", candidates)
+			}
+			slots := []repo.ReviewSlot{
+				{PullRequestID: "pr-1", AuthorID: "author", ReviewerIDs: []string{"u1", "u2", "u4"}, OldReviewerID: "u1"},
+				{PullRequestID: "pr-1", AuthorID: "author", ReviewerIDs: []string{"u1", "u2", "u4"}, OldReviewerID: "u4"},
+			}
+			// u5 was deactivated after being picked.
+			plan([]string{"u3"}, slots)
+
+			reopened := pr
+			reopened.Status = api.PullRequestStatusOPEN
+			reopened.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+			for _, slot := range slots {
+				if slot.NewReviewerID != "" {
+					reopened.AssignedReviewers[slices.Index(reopened.AssignedReviewers, slot.OldReviewerID)] = slot.NewReviewerID
+				}
+			}
+			return reopened, slots, nil
+		},
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			switch id {
+			case "u1", "u4":
+				return api.User{UserId: id, TeamName: "team"}, nil
+			}
+			return api.User{UserId: id, TeamName: "team", IsActive: true}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "author", TeamName: "team", IsActive: true},
+				{UserId: "u2", TeamName: "team", IsActive: true},
+				{UserId: "u3", TeamName: "team", IsActive: true},
+				{UserId: "u5", TeamName: "team", IsActive: true},
+			}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.RoundRobin}, nil
+		},
+		getRotationCursor: func(context.Context, string) (string, error) {
+			return "", nil
+		},
+	})
+
+	got, warnings, err := svc.ReopenPullRequest(context.Background(), "pr-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != api.PullRequestStatusOPEN {
+		t.Fatalf("expected OPEN status, got %s", got.Status)
+	}
+	if !reflect.DeepEqual(got.AssignedReviewers, []string{"u3", "u2", "u4"}) {
+		t.Fatalf("expected reviewers [u3 u2 u4], got %v", got.AssignedReviewers)
+	}
+	if len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) || !strings.Contains(warnings[0].Message, "u4") {
+		t.Fatalf("expected a NO_CANDIDATE warning for u4, got %+v", warnings)
+	}
+}
+
+func TestService_ReopenPullRequest_TeamlessReviewer(t *testing.T) {
+	var candidates []string
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(_ context.Context, id string) (api.PullRequest, error) {
+			return api.PullRequest{PullRequestId: id, AuthorId: "author", Status: api.PullRequestStatusCLOSED, AssignedReviewers: []string{"gone"}}, nil
+		},
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			if id == "gone" {
+				return api.User{UserId: id}, nil
+			}
+			return api.User{UserId: id, TeamName: "team", IsActive: true}, nil
+		},
+		listActiveUsersInTeam: func(_ context.Context, team string) ([]api.User, error) {
+			if team != "team" {
+				t.Fatalf("expected the author's team, got %q", team)
+			}
+			return []api.User{{UserId: "author", TeamName: team, IsActive: true}, {UserId: "u2", TeamName: team, IsActive: true}}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		reopenPullRequest: func(_ context.Context, id string, c []string, _ repo.HandoffPlan) (api.PullRequest, []repo.ReviewSlot, error) {
+			candidates = c
+			return api.PullRequest{PullRequestId: id, Status: api.PullRequestStatusOPEN}, nil, nil
+		},
+	})
+
+	if _, _, err := svc.ReopenPullRequest(context.Background(), "pr-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(candidates, []string{"u2"}) {
+		t.Fatalf("expected u2 from the author's team to replace the teamless reviewer, got %v", candidates)
+	}
+}
+
+func TestService_CreatePullRequest_DraftSkipsAssignment(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			if !pr.Draft {
+				t.Fatalf("expected draft pull request")
+			}
+			if len(assignment.ReviewerIDs) != 0 || assignment.Rotation != nil {
+				t.Fatalf("draft must not get reviewers, got %+v", assignment)
+			}
+			return api.PullRequest{PullRequestId: pr.ID, AuthorId: pr.AuthorID, Draft: &pr.Draft}, nil
+		},
+	})
+
+	req := createRequest("pr-1", "author")
+	draft := true
+	req.Draft = &draft
+
+	_, warnings, err := svc.CreatePullRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings for a draft, got %+v", warnings)
+	}
+}
+
+func TestService_MarkPullRequestReady_AssignsReviewers(t *testing.T) {
+	draft := true
+	var got []string
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(_ context.Context, id string) (api.PullRequest, error) {
+			return api.PullRequest{PullRequestId: id, AuthorId: "author", Status: api.PullRequestStatusOPEN, Draft: &draft}, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "author", TeamName: "team", IsActive: true},
+				{UserId: "u1", TeamName: "team", IsActive: true},
+			}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		markPullRequestReady: func(_ context.Context, id string, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: id, AuthorId: "author", AssignedReviewers: got}, nil
+		},
+	})
+
+	_, warnings, err := svc.MarkPullRequestReady(context.Background(), "pr-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "u1" {
+		t.Fatalf("expected reviewers [u1], got %v", got)
+	}
+	if len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected NO_CANDIDATE warning, got %+v", warnings)
+	}
+}
+
+func TestService_MarkPullRequestDraft_KeepsReviewers(t *testing.T) {
+	ctx := context.Background()
+	pr := api.PullRequest{PullRequestId: "pr-1", AuthorId: "author", Status: api.PullRequestStatusOPEN, AssignedReviewers: []string{"u1"}}
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+			return pr, nil
+		},
+		markPullRequestDraft: func(context.Context, string) (api.PullRequest, error) {
+			draft := true
+			pr.Draft = &draft
+			return pr, nil
+		},
+		markPullRequestReady: func(_ context.Context, _ string, assignment repo.Assignment) (api.PullRequest, error) {
+			if len(assignment.ReviewerIDs) != 0 || assignment.Rotation != nil || len(assignment.FallbackIDs) != 0 {
+				t.Fatalf("expected no new reviewers, got %+v", assignment)
+			}
+			pr.Draft = nil
+			return pr, nil
+		},
+	})
+
+	got, err := svc.MarkPullRequestDraft(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Draft == nil || !*got.Draft || len(got.AssignedReviewers) != 1 {
+		t.Fatalf("expected a draft that keeps its reviewer, got %+v", got)
+	}
+
+	got, warnings, err := svc.MarkPullRequestReady(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.AssignedReviewers) != 1 || len(warnings) != 0 {
+		t.Fatalf("expected the reviewer to be kept without warnings, got %+v %+v", got, warnings)
+	}
+
+	pr.Status = api.PullRequestStatusMERGED
+	_, err = svc.MarkPullRequestDraft(ctx, "pr-1")
+	assertServiceErrorCode(t, err, api.PRMERGED)
+}
+
+func TestService_SetUserActive_DeactivationHandsOffReviews(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			return api.User{UserId: id, TeamName: "team", IsActive: true}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "u2"}, {UserId: "u3"}, {UserId: "u4"}}, nil
+		},
+		deactivateUser: func(_ context.Context, id string, plan repo.HandoffPlan) (api.User, []repo.ReviewSlot, error) {
+			slots := []repo.ReviewSlot{
+				{PullRequestID: "pr-1", AuthorID: "u2", ReviewerIDs: []string{"u1", "u3"}, OldReviewerID: "u1"},
+				{PullRequestID: "pr-2", AuthorID: "u4", ReviewerIDs: []string{"u1", "u2", "u3"}, OldReviewerID: "u1"},
+			}
+			plan([]string{"u2", "u3", "u4"}, slots)
+			return api.User{UserId: id, TeamName: "team"}, slots, nil
+		},
+	})
+
+	user, report, err := svc.SetUserActive(context.Background(), "u1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.IsActive {
+		t.Fatalf("expected inactive user, got %+v", user)
+	}
+	if report == nil || len(report.Reassigned) != 1 || len(report.Unassigned) != 1 {
+		t.Fatalf("expected one reassigned and one unassigned slot, got %+v", report)
+	}
+	if got := report.Reassigned[0]; got.PullRequestId != "pr-1" || got.NewReviewerId == nil || *got.NewReviewerId != "u4" {
+		t.Fatalf("expected pr-1 to go to u4, got %+v", got)
+	}
+	if got := report.Unassigned[0]; got.PullRequestId != "pr-2" || got.NewReviewerId != nil {
+		t.Fatalf("expected pr-2 to stay unassigned, got %+v", got)
+	}
+}
+
+func TestService_AddTeamMembers_DeactivationHandsOffReviews(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "u1"}, {UserId: "u2"}, {UserId: "u3"}}, nil
+		},
+		addTeamMembers: func(_ context.Context, name string, members []api.TeamMember, plan repo.HandoffPlan) (api.Team, []repo.ReviewSlot, error) {
+			if plan == nil {
+				t.Fatalf("expected a handoff plan for an inactive member")
+			}
+			slots := []repo.ReviewSlot{
+				{PullRequestID: "pr-1", AuthorID: "u2", ReviewerIDs: []string{"u1"}, OldReviewerID: "u1"},
+			}
+			plan([]string{"u2", "u3"}, slots)
+			return api.Team{TeamName: name, Members: members}, slots, nil
+		},
+	})
+
+	_, report, err := svc.AddTeamMembers(context.Background(), api.Team{
+		TeamName: "team",
+		Members:  []api.TeamMember{{UserId: "u1", Username: "Alice", IsActive: false}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Reassigned) != 1 || len(report.Unassigned) != 0 {
+		t.Fatalf("expected one reassigned slot, got %+v", report)
+	}
+	if got := report.Reassigned[0]; got.PullRequestId != "pr-1" || got.OldReviewerId != "u1" || got.NewReviewerId == nil || *got.NewReviewerId != "u3" {
+		t.Fatalf("expected pr-1 to go from u1 to u3, got %+v", got)
+	}
+}
+
+func TestService_SetUserActive_HandoffKeepsSenior(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			return leveledUser(id, api.Senior), nil
+		},
+		getTeamSettings: mentoringSettings(true, false),
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{leveledUser("u2", api.Mid), leveledUser("u3", api.Senior), leveledUser("u4", api.Junior)}, nil
+		},
+		deactivateUser: func(_ context.Context, id string, plan repo.HandoffPlan) (api.User, []repo.ReviewSlot, error) {
+			slots := []repo.ReviewSlot{
+				{PullRequestID: "pr-1", AuthorID: "u4", ReviewerIDs: []string{"u1", "u2"}, OldReviewerID: "u1", OldLevel: api.Senior, OtherLevels: []api.UserLevel{api.Mid}},
+				{PullRequestID: "pr-2", AuthorID: "u4", ReviewerIDs: []string{"u1", "u3"}, OldReviewerID: "u1", OldLevel: api.Senior, OtherLevels: []api.UserLevel{api.Senior}},
+				{PullRequestID: "pr-3", AuthorID: "u3", ReviewerIDs: []string{"u1"}, OldReviewerID: "u1", OldLevel: api.Senior},
+			}
+			plan([]string{"u2", "u3", "u4"}, slots)
+			return api.User{UserId: id}, slots, nil
+		},
+	})
+
+	_, report, err := svc.SetUserActive(context.Background(), "u1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]string{}
+	for _, h := range report.Reassigned {
+		got[h.PullRequestId] = *h.NewReviewerId
+	}
+	if got["pr-1"] != "u3" {
+		t.Fatalf("expected senior u3 to replace the only senior on pr-1, got %v", got)
+	}
+	if got["pr-2"] == "" {
+		t.Fatalf("expected pr-2, which keeps a senior, to be reassigned to anyone, got %v", got)
+	}
+	if len(report.Unassigned) != 1 || report.Unassigned[0].PullRequestId != "pr-3" {
+		t.Fatalf("expected pr-3, authored by the only other senior, to stay unassigned, got %+v", report)
+	}
+}
+
+func TestService_DeactivateTeamUsers(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return nil, nil
+		},
+		deactivateUsers: func(_ context.Context, team string, ids []string, allExcept bool, plan repo.HandoffPlan) ([]api.User, []repo.ReviewSlot, error) {
+			if len(ids) != 1 || ids[0] != "u1" || !allExcept {
+				t.Fatalf("expected deduplicated all-except [u1], got %v %v", ids, allExcept)
+			}
+			return nil, nil, repo.ErrNotTeamMember
+		},
+	})
+
+	allExcept := true
+	_, _, err := svc.DeactivateTeamUsers(context.Background(), api.PostTeamDeactivateUsersJSONBody{
+		TeamName:  "team",
+		UserIds:   []string{"u1", "u1"},
+		AllExcept: &allExcept,
+	})
+	assertServiceErrorCode(t, err, api.NOTFOUND)
+
+	_, _, err = svc.DeactivateTeamUsers(context.Background(), api.PostTeamDeactivateUsersJSONBody{TeamName: "team"})
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_DeleteTeam_OpenPullRequests(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		deleteTeam: func(context.Context, string) ([]api.User, error) {
+			return nil, repo.ErrTeamHasOpenPRs
+		},
+	})
+
+	_, err := svc.DeleteTeam(context.Background(), "team")
+	assertServiceErrorCode(t, err, api.TEAMHASOPENPRS)
+}
+
+func TestService_RemoveTeamMembers_OpenPullRequests(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return nil, nil
+		},
+		removeTeamMembers: func(context.Context, string, []string, repo.HandoffPlan) (api.Team, []api.User, []repo.ReviewSlot, error) {
+			return api.Team{}, nil, nil, repo.ErrTeamHasOpenPRs
+		},
+	})
+
+	_, _, _, err := svc.RemoveTeamMembers(context.Background(), "team", []string{"u1"})
+	assertServiceErrorCode(t, err, api.TEAMHASOPENPRS)
+}
+
+func teamlessAuthorRepo(pr api.PullRequest) *mockRepo {
+	return &mockRepo{
+		getPullRequest: func(context.Context, string) (api.PullRequest, error) {
+			return pr, nil
+		},
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			return api.User{UserId: id}, nil
+		},
+		getTeamSettings: func(context.Context, string) (api.TeamSettings, error) {
+			return api.TeamSettings{}, repo.ErrNotFound
+		},
+	}
+}
+
+func TestService_MergePullRequest_TeamlessAuthor(t *testing.T) {
+	r := teamlessAuthorRepo(api.PullRequest{PullRequestId: "pr-1", AuthorId: "author", Status: api.PullRequestStatusOPEN})
+	r.markPullRequestMerged = func(_ context.Context, id string, force bool) (api.PullRequest, error) {
+		if force {
+			t.Errorf("expected a regular merge")
+		}
+		return api.PullRequest{PullRequestId: id, Status: api.PullRequestStatusMERGED, ForceMerged: &force}, nil
+	}
+
+	pr, err := newTestService(r).MergePullRequest(context.Background(), "pr-1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Status != api.PullRequestStatusMERGED {
+		t.Fatalf("expected merged PR, got %+v", pr)
+	}
+}
+
+func TestService_MarkPullRequestReady_TeamlessAuthor(t *testing.T) {
+	draft := true
+	r := teamlessAuthorRepo(api.PullRequest{PullRequestId: "pr-1", AuthorId: "author", Status: api.PullRequestStatusOPEN, Draft: &draft})
+	r.markPullRequestReady = func(_ context.Context, id string, assignment repo.Assignment) (api.PullRequest, error) {
+		if len(assignment.ReviewerIDs) != 0 || assignment.Rotation != nil {
+			t.Errorf("expected an empty assignment, got %+v", assignment)
+		}
+		return api.PullRequest{PullRequestId: id, AuthorId: "author", Status: api.PullRequestStatusOPEN}, nil
+	}
+
+	_, warnings, err := newTestService(r).MarkPullRequestReady(context.Background(), "pr-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected NO_CANDIDATE warning, got %+v", warnings)
+	}
+}
+
+func TestService_SetUserActive_TeamlessUser(t *testing.T) {
+	r := teamlessAuthorRepo(api.PullRequest{})
+	r.setUserActive = func(_ context.Context, id string, active bool) (api.User, error) {
+		return api.User{UserId: id, IsActive: active}, nil
+	}
+
+	user, report, err := newTestService(r).SetUserActive(context.Background(), "u1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.IsActive || report == nil || len(report.Reassigned)+len(report.Unassigned) != 0 {
+		t.Fatalf("expected an inactive user with an empty report, got %+v %+v", user, report)
+	}
+}
+
+func TestService_MoveUserToTeam_WithoutHandOff(t *testing.T) {
+	svc := newTestService(&mockRepo{
+		moveUserToTeam: func(_ context.Context, userID, team string, plan repo.HandoffPlan) (api.User, string, []repo.ReviewSlot, error) {
+			if plan != nil {
+				t.Fatalf("expected no handoff plan")
+			}
+			return api.User{UserId: userID, TeamName: team}, "old", nil, nil
+		},
+	})
+
+	user, from, report, err := svc.MoveUserToTeam(context.Background(), api.PostUsersMoveTeamJSONBody{UserId: "u1", TeamName: "new"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.TeamName != "new" || from != "old" || report != nil {
+		t.Fatalf("unexpected move result: %+v %q %+v", user, from, report)
+	}
+}
+
+func TestService_AddUnavailability_InvalidWindow(t *testing.T) {
+	svc := newTestService(&mockRepo{})
+
+	now := time.Now()
+	_, _, err := svc.AddUnavailability(context.Background(), api.PostUsersAvailabilityAddJSONBody{
+		UserId:   "u1",
+		StartsAt: now,
+		EndsAt:   now.Add(-time.Hour),
+	})
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_AddUnavailability_HandoffWindow(t *testing.T) {
+	var planned []bool
+	svc := newTestService(&mockRepo{
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			return api.User{UserId: id, TeamName: "team", IsActive: true}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return nil, nil
+		},
+		addUnavailability: func(_ context.Context, w api.UnavailabilityWindow, plan repo.HandoffPlan) (api.UnavailabilityWindow, []repo.ReviewSlot, error) {
+			planned = append(planned, plan != nil)
+			return w, nil, nil
+		},
+	})
+	reassign := true
+	add := func(starts, ends time.Time) (api.UnavailabilityWindow, *api.ReassignmentReport, error) {
+		return svc.AddUnavailability(context.Background(), api.PostUsersAvailabilityAddJSONBody{
+			UserId:          "u1",
+			StartsAt:        starts,
+			EndsAt:          ends,
+			ReassignReviews: &reassign,
+		})
+	}
+
+	now := time.Now()
+	_, _, err := add(now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+
+	if w, report, err := add(now.Add(-time.Hour), now.Add(time.Hour)); err != nil || report == nil || w.HandoffAt != nil {
+		t.Fatalf("expected a handoff for a current window, got %+v, %+v, %v", w, report, err)
+	}
+	if w, report, err := add(now.Add(time.Hour), now.Add(48*time.Hour)); err != nil || report == nil || w.HandoffAt != nil {
+		t.Fatalf("expected a handoff for a window starting within the lead time, got %+v, %+v, %v", w, report, err)
+	}
+	starts := now.AddDate(0, 6, 0)
+	w, report, err := add(starts, now.AddDate(0, 7, 0))
+	if err != nil || report != nil {
+		t.Fatalf("expected no handoff yet for a window months away, got %+v, %v", report, err)
+	}
+	if w.HandoffAt == nil || !w.HandoffAt.Equal(starts.Add(-handoffLeadTime)) {
+		t.Fatalf("expected the handoff to be scheduled a day before the window, got %v", w.HandoffAt)
+	}
+	if len(planned) != 3 || !planned[0] || !planned[1] || planned[2] {
+		t.Fatalf("expected handoff plans [true true false], got %v", planned)
+	}
+}
+
+func TestService_HandOffDueWindows(t *testing.T) {
+	var handedOff []int64
+	svc := newTestService(&mockRepo{
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "u2"}, {UserId: "u3"}}, nil
+		},
+		listDueHandoffs: func(context.Context, int) ([]repo.DueHandoff, error) {
+			return []repo.DueHandoff{
+				{WindowID: 1, UserID: "u1", TeamName: "team"},
+				{WindowID: 2, UserID: "u4", TeamName: "moved"},
+				{WindowID: 3, UserID: "u5"},
+			}, nil
+		},
+		handOffDueWindow: func(_ context.Context, id int64, team string, plan repo.HandoffPlan) ([]repo.ReviewSlot, error) {
+			if id == 2 {
+				return nil, repo.ErrNotTeamMember
+			}
+			if (team == "") != (plan == nil) {
+				t.Fatalf("window %d: expected a plan only for a user with a team", id)
+			}
+			handedOff = append(handedOff, id)
+			if plan == nil {
+				return nil, nil
+			}
+			slots := []repo.ReviewSlot{{PullRequestID: "pr-1", AuthorID: "u2", ReviewerIDs: []string{"u1", "u2"}, OldReviewerID: "u1"}}
+			plan([]string{"u2", "u3"}, slots)
+			if slots[0].NewReviewerID != "u3" {
+				t.Fatalf("expected u3 to take over pr-1, got %+v", slots[0])
+			}
+			return slots, nil
+		},
+	})
+
+	n, err := svc.HandOffDueWindows(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || !reflect.DeepEqual(handedOff, []int64{1, 3}) {
+		t.Fatalf("expected windows [1 3] to be handed off, got %d %v", n, handedOff)
+	}
+}
+
+func TestService_CreatePullRequest_RespectsCapacity(t *testing.T) {
+	limit := 2
+	var got []string
+	members := []api.TeamMember{
+		{UserId: "author", IsActive: true},
+		{UserId: "u1", IsActive: true},
+		{UserId: "u2", IsActive: true},
+	}
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{
+				{UserId: "author", TeamName: "team", IsActive: true},
+				{UserId: "u1", TeamName: "team", IsActive: true},
+				{UserId: "u2", TeamName: "team", IsActive: true, MaxOpenReviews: &limit},
+			}, nil
+		},
+		countOpenReviews: func(context.Context, []string) (map[string]int, error) {
+			return map[string]int{"u1": 1, "u2": 2}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, MaxOpenReviews: &limit}, nil
+		},
+		getTeam: func(_ context.Context, team string) (api.Team, error) {
+			return api.Team{TeamName: team, Members: members}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AuthorId: pr.AuthorID, AssignedReviewers: got}, nil
+		},
+	})
+
+	if _, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "u1" {
+		t.Fatalf("expected reviewers [u1], got %v", got)
+	}
+
+	limit = 1
+	_, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-2", "author"))
+	assertServiceErrorCode(t, err, api.NOCANDIDATE)
+	if msg := err.(*Error).Msg; strings.Contains(msg, "unavailable") {
+		t.Fatalf("nobody is on leave, got %q", msg)
+	}
+
+	members = append(members, api.TeamMember{UserId: "u3", IsActive: true})
+	_, _, err = svc.CreatePullRequest(context.Background(), createRequest("pr-2", "author"))
+	assertServiceErrorCode(t, err, api.NOCANDIDATE)
+	if msg := err.(*Error).Msg; !strings.Contains(msg, "1 unavailable, 2 at capacity") {
+		t.Fatalf("expected the member on leave to be named as a reason, got %q", msg)
+	}
+
+	req := createRequest("pr-2", "author")
+	zero := 0
+	req.ReviewersCount = &zero
+	if _, _, err := svc.CreatePullRequest(context.Background(), req); err != nil {
+		t.Fatalf("no reviewers were requested, got %v", err)
+	}
+
+	req = createRequest("pr-2", "author")
+	ignore := true
+	req.IgnoreCapacity = &ignore
+	if _, _, err := svc.CreatePullRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected both reviewers when ignoring capacity, got %v", got)
+	}
+}
+
+func TestService_CreatePullRequest_FillsFromFallbackTeams(t *testing.T) {
+	teams := map[string][]api.User{
+		"team":  {{UserId: "author", TeamName: "team"}, {UserId: "u1", TeamName: "team"}},
+		"empty": {},
+		"ops":   {{UserId: "o1", TeamName: "ops"}},
+		"infra": {{UserId: "i1", TeamName: "infra"}, {UserId: "i2", TeamName: "infra"}},
+	}
+	var got repo.Assignment
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(_ context.Context, team string) ([]api.User, error) {
+			return teams[team], nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			settings := api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}
+			if team == "team" {
+				settings.FallbackTeams = &[]string{"empty", "ops", "infra"}
+			}
+			return settings, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment
+			return api.PullRequest{PullRequestId: pr.ID}, nil
+		},
+	})
+
+	count := 3
+	req := createRequest("pr-1", "author")
+	req.ReviewersCount = &count
+	if _, _, err := svc.CreatePullRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.ReviewerIDs) != 1 || got.ReviewerIDs[0] != "u1" {
+		t.Fatalf("expected own reviewer u1, got %v", got.ReviewerIDs)
+	}
+	if len(got.FallbackIDs) != 2 || got.FallbackIDs[0] != "o1" || !strings.HasPrefix(got.FallbackIDs[1], "i") {
+		t.Fatalf("expected o1 then one of infra as fallback, got %v", got.FallbackIDs)
+	}
+}
+
+func TestService_CreatePullRequest_AssignsCodeOwner(t *testing.T) {
+	var got []string
+	available := true
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "author"}, {UserId: "u1"}, {UserId: "u2"}, {UserId: "u3"}}, nil
+		},
+		listActiveUsers: func(_ context.Context, ids []string) ([]api.User, error) {
+			if len(ids) != 1 || ids[0] != "o1" {
+				t.Fatalf("expected owner o1 to be looked up, got %v", ids)
+			}
+			if !available {
+				return nil, nil
+			}
+			return []api.User{{UserId: "o1", TeamName: "ops"}}, nil
+		},
+		getCodeOwners: func(_ context.Context, team string) (api.CodeOwners, error) {
+			return api.CodeOwners{TeamName: team, Content: "*  @u1\n/internal/repo/  @o1\n"}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: got}, nil
+		},
+	})
+
+	req := createRequest("pr-1", "author")
+	req.ChangedPaths = &[]string{"/internal/repo/repo.go"}
+	_, warnings, err := svc.CreatePullRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "o1" || got[1] == "o1" || len(warnings) != 0 {
+		t.Fatalf("expected owner o1 plus one team reviewer, got %v %v", got, warnings)
+	}
+
+	available = false
+	_, warnings, err = svc.CreatePullRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected two team reviewers and a missing owner warning, got %v %v", got, warnings)
+	}
+
+	req.ChangedPaths = &[]string{" "}
+	_, _, err = svc.CreatePullRequest(context.Background(), req)
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_CreatePullRequest_SkillMatch(t *testing.T) {
+	var got []string
+	users := []api.User{
+		{UserId: "author", Tags: &[]string{"sql"}},
+		{UserId: "u1", Tags: &[]string{"go"}},
+		{UserId: "u2", Tags: &[]string{"sql", "migrations"}},
+		{UserId: "u3", Tags: &[]string{}},
+		{UserId: "u4", Tags: &[]string{"infra", "sql"}},
+	}
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return users, nil
+		},
+		listActiveUsers: func(_ context.Context, ids []string) ([]api.User, error) {
+			if len(ids) != 4 {
+				t.Fatalf("expected the 4 candidates to be looked up, got %v", ids)
+			}
+			return users[1:], nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.SkillMatch}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: got}, nil
+		},
+	})
+
+	req := createRequest("pr-1", "author")
+	req.Labels = &[]string{"SQL", " migrations", "sql"}
+	if _, _, err := svc.CreatePullRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "u2" || got[1] != "u4" {
+		t.Fatalf("expected best matches [u2 u4], got %v", got)
+	}
+
+	req.Labels = &[]string{""}
+	_, _, err := svc.CreatePullRequest(context.Background(), req)
+	assertServiceErrorCode(t, err, api.BADREQUEST)
+}
+
+func TestService_CreatePullRequest_DiverseAvoidsRecentReviewers(t *testing.T) {
+	var got []string
+	svc := newTestService(&mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "author"}, {UserId: "u1"}, {UserId: "u2"}, {UserId: "u3"}}, nil
+		},
+		countRecentReviews: func(_ context.Context, authorID string, n int) (map[string]int, error) {
+			if authorID != "author" || n != 3 {
+				t.Fatalf("expected the last 3 PRs of author, got %d of %s", n, authorID)
+			}
+			return map[string]int{"u1": 3, "u2": 1}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			count, window := 1, 3
+			return api.TeamSettings{
+				TeamName:         team,
+				ReviewerStrategy: api.Diverse,
+				ReviewersCount:   &count,
+				DiversityWindow:  &window,
+			}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: got}, nil
+		},
+	})
+
+	picks := map[string]int{}
+	for i := 0; i < 700; i++ {
+		if _, _, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected 1 reviewer, got %v", got)
+		}
+		picks[got[0]]++
+	}
+	// Expected shares are 1/4 : 1/2 : 1 for u1, u2 and u3.
+	if !(picks["u1"] < picks["u2"] && picks["u2"] < picks["u3"]) {
+		t.Fatalf("expected recent reviewers to be picked less often, got %v", picks)
+	}
+}
+
+func TestService_CreatePullRequest_DeterministicPerPR(t *testing.T) {
+	var got []string
+	r := &mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			users := []api.User{{UserId: "author"}}
+			for i := 1; i <= 8; i++ {
+				users = append(users, api.User{UserId: fmt.Sprintf("u%d", i)})
+			}
+			return users, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: got}, nil
+		},
+	}
+
+	assign := func(svc *Service, prID string) string {
+		if _, _, err := svc.CreatePullRequest(context.Background(), createRequest(prID, "author")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return strings.Join(got, ",")
+	}
+
+	first := newTestService(r)
+	first.SetDeterministic(true)
+	second := newTestService(r)
+	second.SetDeterministic(true)
+
+	distinct := map[string]struct{}{}
+	for i := 0; i < 20; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		want := assign(first, prID)
+		for j := 0; j < 3; j++ {
+			if again := assign(second, prID); again != want {
+				t.Fatalf("%s: expected %s on replay, got %s", prID, want, again)
+			}
+		}
+		distinct[want] = struct{}{}
+	}
+	if len(distinct) < 2 {
+		t.Fatalf("expected different pull requests to get different reviewers, got %v", distinct)
+	}
+
+	seeded := newTestService(r)
+	seeded.SetSeed(42)
+	seeded.SetDeterministic(true)
+	changed := false
+	for i := 0; i < 20 && !changed; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		changed = assign(seeded, prID) != assign(first, prID)
+	}
+	if !changed {
+		t.Fatal("expected the seed to change per-PR selection")
+	}
+}
+
+func TestLockedRand_Concurrent(t *testing.T) {
+	rng := newLockedRand(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if n := rng.Intn(10); n < 0 || n >= 10 {
+					t.Errorf("out of range: %d", n)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestService_ApplyPullRequestEvent(t *testing.T) {
+	var created repo.NewPullRequest
+	forced, merged := false, false
+	exists := false
+	required := 1
+	svc := newTestService(&mockRepo{
+		externalLoginUser: func(_ context.Context, provider api.GitProvider, login string) (string, error) {
+			if provider == api.Github && login == "octocat" {
+				return "author", nil
+			}
+			return "", repo.ErrNotFound
+		},
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return exists, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return api.User{UserId: "author", TeamName: "team"}, nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return []api.User{{UserId: "author"}, {UserId: "u1"}}, nil
+		},
+		getTeamSettings: func(_ context.Context, team string) (api.TeamSettings, error) {
+			return api.TeamSettings{TeamName: team, ReviewerStrategy: api.Random, RequiredApprovals: &required}, nil
+		},
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			created = pr
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: assignment.ReviewerIDs}, nil
+		},
+		getPullRequest: func(_ context.Context, id string) (api.PullRequest, error) {
+			if id != "acme/api#42" {
+				return api.PullRequest{}, repo.ErrNotFound
+			}
+			return api.PullRequest{PullRequestId: id, Status: api.PullRequestStatusOPEN}, nil
+		},
+		markPullRequestMerged: func(_ context.Context, id string, force bool) (api.PullRequest, error) {
+			forced, merged = force, true
+			return api.PullRequest{PullRequestId: id, Status: api.PullRequestStatusMERGED}, nil
+		},
+	})
+	ctx := context.Background()
+
+	opened := PullRequestEvent{
+		Provider:      api.Github,
+		Action:        PullRequestOpened,
+		PullRequestID: "acme/api#42",
+		Title:         "Add search",
+		AuthorLogin:   "OctoCat",
+		Labels:        []string{"Backend"},
+	}
+	res, err := svc.ApplyPullRequestEvent(ctx, opened)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Result != api.Applied || created.ID != "acme/api#42" || created.AuthorID != "author" {
+		t.Fatalf("expected the pull request to be created by author, got %+v and %+v", res, created)
+	}
+	if len(created.Labels) != 1 || created.Labels[0] != "backend" {
+		t.Fatalf("expected labels [backend], got %v", created.Labels)
+	}
+	if created.Provider != api.Github {
+		t.Fatalf("expected the pull request to mirror github, got %q", created.Provider)
+	}
+
+	exists = true
+	if res, err = svc.ApplyPullRequestEvent(ctx, opened); err != nil || res.Result != api.Ignored {
+		t.Fatalf("expected a redelivered event to be ignored, got %+v, %v", res, err)
+	}
+
+	opened.AuthorLogin = "stranger"
+	if res, err = svc.ApplyPullRequestEvent(ctx, opened); err != nil || res.Result != api.Ignored {
+		t.Fatalf("expected an unmapped login to be ignored, got %+v, %v", res, err)
+	}
+
+	mergedEv := PullRequestEvent{Provider: api.Github, Action: PullRequestMerged, PullRequestID: "acme/api#42"}
+	if res, err = svc.ApplyPullRequestEvent(ctx, mergedEv); err != nil || res.Result != api.Applied || !merged || forced {
+		t.Fatalf("expected an unapproved merge that isn't recorded as forced, got %+v, %v", res, err)
+	}
+
+	mergedEv.PullRequestID = "acme/api#7"
+	if res, err = svc.ApplyPullRequestEvent(ctx, mergedEv); err != nil || res.Result != api.Ignored {
+		t.Fatalf("expected an untracked pull request to be ignored, got %+v, %v", res, err)
+	}
+
+	labeled := PullRequestEvent{Provider: api.Github, Action: "labeled", PullRequestID: "acme/api#42"}
+	if res, err = svc.ApplyPullRequestEvent(ctx, labeled); err != nil || res.Result != api.Ignored {
+		t.Fatalf("expected an unhandled action to be ignored, got %+v, %v", res, err)
+	}
+}
+
+func TestService_SyncReviewers(t *testing.T) {
+	type call struct {
+		method, path string
+		reviewers    []string
+	}
+	var calls []call
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, call{r.Method, r.URL.Path, body.Reviewers})
+		switch r.URL.Path {
+		case "/repos/acme/api/pulls/2/requested_reviewers":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/repos/acme/api/pulls/3/requested_reviewers":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Reviews may only be requested from collaborators."}`))
+		}
+	}))
+	defer gh.Close()
+
+	completed := map[int64]bool{}
+	retried := map[int64]time.Duration{}
+	failed := map[int64]string{}
+	svc := newTestService(&mockRepo{
+		claimReviewerSyncJobs: func(context.Context, int, time.Duration) ([]repo.ReviewerSyncJob, error) {
+			return []repo.ReviewerSyncJob{
+				{ID: 1, PullRequestID: "acme/api#1", Provider: api.Github, AddIDs: []string{"u1", "u3"}, RemoveIDs: []string{"u2"}, Attempts: 1},
+				{ID: 2, PullRequestID: "acme/api#2", Provider: api.Github, AddIDs: []string{"u1"}, Attempts: 2},
+				{ID: 3, PullRequestID: "acme/api#3", Provider: api.Github, AddIDs: []string{"u1"}, Attempts: 1},
+				{ID: 4, PullRequestID: "acme/api#2", Provider: api.Github, AddIDs: []string{"u1"}, Attempts: reviewerSyncMaxAttempts},
+				{ID: 5, PullRequestID: "group/project!7", Provider: api.Gitlab, AddIDs: []string{"u1"}, Attempts: 1},
+			}, nil
+		},
+		externalLoginsOf: func(_ context.Context, _ api.GitProvider, ids []string) (map[string]string, error) {
+			logins := map[string]string{}
+			for _, id := range ids {
+				if id != "u3" {
+					logins[id] = "gh-" + id
+				}
+			}
+			return logins, nil
+		},
+		completeReviewerSync: func(_ context.Context, id int64) error {
+			completed[id] = true
+			return nil
+		},
+		retryReviewerSync: func(_ context.Context, id int64, delay time.Duration, _ string) error {
+			retried[id] = delay
+			return nil
+		},
+		failReviewerSync: func(_ context.Context, id int64, lastErr string) error {
+			failed[id] = lastErr
+			return nil
+		},
+	})
+	svc.SetReviewRequester(api.Github, github.NewClient(gh.URL, "t0ken"))
+
+	n, err := svc.SyncReviewers(context.Background())
+	if err != nil || n != 5 {
+		t.Fatalf("expected 5 jobs synced, got %d, %v", n, err)
+	}
+
+	first := []call{
+		{http.MethodDelete, "/repos/acme/api/pulls/1/requested_reviewers", []string{"gh-u2"}},
+		{http.MethodPost, "/repos/acme/api/pulls/1/requested_reviewers", []string{"gh-u1"}},
+	}
+	if len(calls) < 2 || !reflect.DeepEqual(calls[:2], first) {
+		t.Fatalf("expected %+v first, got %+v", first, calls)
+	}
+	if !completed[1] || len(completed) != 1 {
+		t.Fatalf("expected only job 1 completed, got %v", completed)
+	}
+	if len(retried) != 1 || retried[2] != 2*reviewerSyncBaseDelay {
+		t.Fatalf("expected job 2 retried after %v, got %v", 2*reviewerSyncBaseDelay, retried)
+	}
+	if len(failed) != 3 || !strings.Contains(failed[3], "collaborators") || failed[4] == "" || failed[5] == "" {
+		t.Fatalf("expected jobs 3, 4 and 5 failed, got %v", failed)
+	}
+}
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent map[string][]notify.Message
+	errs map[string]error
+}
+
+func (f *fakeSender) Send(_ context.Context, url, secret string, m notify.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if secret != "s3cr3t" {
+		return fmt.Errorf("unexpected secret %q", secret)
+	}
+	if f.sent == nil {
+		f.sent = map[string][]notify.Message{}
+	}
+	f.sent[url] = append(f.sent[url], m)
+	return f.errs[url]
+}
+
+func TestService_DeliverWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	completed := map[int64]bool{}
+	retried := map[int64]time.Duration{}
+	dead := map[int64]string{}
+	svc := newTestService(&mockRepo{
+		claimWebhooks: func(context.Context, int, time.Duration) ([]repo.WebhookDeliveryJob, error) {
+			job := func(id int64, url string, attempts int) repo.WebhookDeliveryJob {
+				return repo.WebhookDeliveryJob{ID: id, SubscriptionID: 1, URL: url, Secret: "s3cr3t",
+					Event: api.PullRequestMerged, Payload: []byte(`{}`), Attempts: attempts}
+			}
+			return []repo.WebhookDeliveryJob{
+				job(1, "http://ok", 1),
+				job(2, "http://flaky", 3),
+				job(3, "http://gone", 1),
+				job(4, "http://flaky", webhookMaxAttempts),
+			}, nil
+		},
+		completeWebhook: func(_ context.Context, id int64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			completed[id] = true
+			return nil
+		},
+		retryWebhook: func(_ context.Context, id int64, delay time.Duration, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			retried[id] = delay
+			return nil
+		},
+		deadLetterWebhook: func(_ context.Context, id int64, lastErr string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			dead[id] = lastErr
+			return nil
+		},
+	})
+
+	if n, err := svc.DeliverWebhooks(context.Background()); err != nil || n != 0 {
+		t.Fatalf("expected nothing delivered without a sender, got %d, %v", n, err)
+	}
+
+	sender := &fakeSender{errs: map[string]error{
+		"http://flaky": &notify.StatusError{StatusCode: http.StatusServiceUnavailable},
+		"http://gone":  &notify.StatusError{StatusCode: http.StatusGone},
+	}}
+	svc.SetWebhookSender(sender)
+	n, err := svc.DeliverWebhooks(context.Background())
+	if err != nil || n != 4 {
+		t.Fatalf("expected 4 deliveries, got %d, %v", n, err)
+	}
+
+	if msgs := sender.sent["http://ok"]; len(msgs) != 1 || msgs[0].DeliveryID != 1 || msgs[0].Event != "pull_request.merged" {
+		t.Fatalf("unexpected messages %+v", msgs)
+	}
+	if !completed[1] || len(completed) != 1 {
+		t.Fatalf("expected only delivery 1 completed, got %v", completed)
+	}
+	if len(retried) != 1 || retried[2] != 4*webhookBaseDelay {
+		t.Fatalf("expected delivery 2 retried after %v, got %v", 4*webhookBaseDelay, retried)
+	}
+	if len(dead) != 2 || !strings.Contains(dead[3], "410") || !strings.Contains(dead[4], "503") {
+		t.Fatalf("expected deliveries 3 and 4 dead-lettered, got %v", dead)
+	}
+}
+
+func TestService_CreateWebhookSubscription_Validation(t *testing.T) {
+	var saved repo.NewWebhookSubscription
+	svc := newTestService(&mockRepo{
+		createSubscription: func(_ context.Context, sub repo.NewWebhookSubscription) (api.WebhookSubscription, error) {
+			saved = sub
+			return api.WebhookSubscription{SubscriptionId: 1, Url: sub.URL, Events: sub.Events}, nil
+		},
+	})
+	ctx := context.Background()
+
+	for name, req := range map[string]api.PostWebhooksSubscriptionsCreateJSONBody{
+		"relative url":  {Url: "/hooks", Secret: "s3cr3t"},
+		"ftp url":       {Url: "ftp://bot.internal/hooks", Secret: "s3cr3t"},
+		"empty secret":  {Url: "https://bot.internal/hooks"},
+		"unknown event": {Url: "https://bot.internal/hooks", Secret: "s3cr3t", Events: &[]api.WebhookEventType{"team.created"}},
+	} {
+		_, err := svc.CreateWebhookSubscription(ctx, req)
+		if svcErr, ok := err.(*Error); !ok || svcErr.Code != api.BADREQUEST {
+			t.Errorf("%s: expected BAD_REQUEST, got %v", name, err)
+		}
+	}
+
+	events := []api.WebhookEventType{api.PullRequestMerged, api.PullRequestCreated, api.PullRequestMerged}
+	if _, err := svc.CreateWebhookSubscription(ctx, api.PostWebhooksSubscriptionsCreateJSONBody{
+		Url:    "https://bot.internal/hooks",
+		Secret: "s3cr3t",
+		Events: &events,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []api.WebhookEventType{api.PullRequestMerged, api.PullRequestCreated}; !reflect.DeepEqual(saved.Events, want) {
+		t.Fatalf("expected events %v, got %v", want, saved.Events)
+	}
+}
+
+func TestService_DispatchOutbox(t *testing.T) {
+	u2 := "u2"
+	pr := api.PullRequest{PullRequestId: "pr-1", AssignedReviewers: []string{"u1"}}
+	message := func(id int64, ev api.WebhookEvent) repo.OutboxMessage {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatalf("encode event: %v", err)
+		}
+		return repo.OutboxMessage{ID: id, Topic: ev.Event, PullRequestID: ev.PullRequest.PullRequestId, Payload: payload}
+	}
+	msgs := []repo.OutboxMessage{
+		message(1, api.WebhookEvent{Event: api.PullRequestCreated, PullRequest: pr}),
+		message(2, api.WebhookEvent{Event: api.PullRequestReviewerReassigned, PullRequest: pr,
+			Reassignment: &api.ReviewHandoff{PullRequestId: "pr-1", OldReviewerId: "u1", NewReviewerId: &u2}}),
+		message(3, api.WebhookEvent{Event: api.PullRequestMerged, PullRequest: pr}),
+	}
+	var routes []repo.OutboxRoute
+	r := &mockRepo{
+		dispatchOutbox: func(_ context.Context, limit int, route func(repo.OutboxMessage) (repo.OutboxRoute, error)) (int, error) {
+			if limit != OutboxBatch {
+				t.Errorf("expected limit %d, got %d", OutboxBatch, limit)
+			}
+			routes = routes[:0]
+			for _, m := range msgs {
+				rt, err := route(m)
+				if err != nil {
+					return 0, err
+				}
+				routes = append(routes, rt)
+			}
+			return len(msgs), nil
+		},
+	}
+
+	if n, err := newTestService(r).DispatchOutbox(context.Background()); err != nil || n != 3 {
+		t.Fatalf("expected 3 dispatched, got %d, %v", n, err)
+	}
+	for i, rt := range routes {
+		if rt.Webhooks || len(rt.SyncProviders) != 0 {
+			t.Errorf("message %d: expected no destinations without sender and requester, got %+v", i+1, rt)
+		}
+	}
+
+	svc := newTestService(r)
+	svc.SetWebhookSender(&fakeSender{})
+	svc.SetReviewRequester(api.Github, github.NewClient("http://127.0.0.1:0", "t0ken"))
+	if _, err := svc.DispatchOutbox(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []repo.OutboxRoute{
+		{Webhooks: true, SyncAddIDs: []string{"u1"}, SyncProviders: []api.GitProvider{api.Github}},
+		{Webhooks: true, SyncAddIDs: []string{"u2"}, SyncRemoveIDs: []string{"u1"}, SyncProviders: []api.GitProvider{api.Github}},
+		{Webhooks: true, SyncProviders: []api.GitProvider{api.Github}},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Fatalf("expected routes %+v, got %+v", want, routes)
+	}
+
+	msgs = []repo.OutboxMessage{{ID: 4, Topic: api.PullRequestMerged, Payload: []byte("{")}}
+	if _, err := svc.DispatchOutbox(context.Background()); err == nil {
+		t.Fatal("expected an error for a malformed payload")
+	}
+}
+
+func leveledUser(id string, level api.UserLevel) api.User {
+	return api.User{UserId: id, TeamName: "team", IsActive: true, Level: &level}
+}
+
+func mentoringSettings(requireSenior, juniorShadow bool) func(context.Context, string) (api.TeamSettings, error) {
+	return func(_ context.Context, team string) (api.TeamSettings, error) {
+		return api.TeamSettings{
+			TeamName:         team,
+			ReviewerStrategy: api.Random,
+			RequireSenior:    &requireSenior,
+			JuniorShadow:     &juniorShadow,
+		}, nil
+	}
+}
+
+func TestService_CreatePullRequest_MentoringPolicy(t *testing.T) {
+	team := []api.User{
+		leveledUser("author", api.Mid),
+		leveledUser("j1", api.Junior),
+		leveledUser("m1", api.Mid),
+		leveledUser("m2", api.Mid),
+		leveledUser("s1", api.Senior),
+	}
+	var got []string
+	mock := &mockRepo{
+		pullRequestExists: func(context.Context, string) (bool, error) {
+			return false, nil
+		},
+		getUser: func(context.Context, string) (api.User, error) {
+			return team[0], nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return team, nil
+		},
+		getTeamSettings: mentoringSettings(true, true),
+		createPullRequest: func(_ context.Context, pr repo.NewPullRequest, assignment repo.Assignment) (api.PullRequest, error) {
+			got = assignment.ReviewerIDs
+			return api.PullRequest{PullRequestId: pr.ID, AssignedReviewers: got}, nil
+		},
+	}
+	svc := newTestService(mock)
+
+	for i := 0; i < 20; i++ {
+		_, warnings, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] != "s1" || got[1] != "j1" || len(warnings) != 0 {
+			t.Fatalf("expected senior s1 with junior shadow j1, got %v %v", got, warnings)
+		}
+	}
+
+	count := 1
+	req := createRequest("pr-1", "author")
+	req.ReviewersCount = &count
+	if _, _, err := svc.CreatePullRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "s1" {
+		t.Fatalf("expected the only slot to go to senior s1, got %v", got)
+	}
+
+	team = team[:4]
+	_, warnings, err := svc.CreatePullRequest(context.Background(), createRequest("pr-1", "author"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "j1" || len(warnings) != 1 || warnings[0].Code != string(api.NOCANDIDATE) {
+		t.Fatalf("expected junior shadow and a missing senior warning, got %v %v", got, warnings)
+	}
+}
+
+func TestService_ReassignReviewer_KeepsSenior(t *testing.T) {
+	team := []api.User{
+		leveledUser("s1", api.Senior),
+		leveledUser("m1", api.Mid),
+		leveledUser("m2", api.Mid),
+		leveledUser("m3", api.Mid),
+		leveledUser("s2", api.Senior),
+	}
+	byID := map[string]api.User{}
+	for _, u := range team {
+		byID[u.UserId] = u
+	}
+	reviewers := []string{"s1", "m1"}
+	var replaced string
+	svc := newTestService(&mockRepo{
+		getPullRequest: func(_ context.Context, id string) (api.PullRequest, error) {
+			return api.PullRequest{
+				PullRequestId:     id,
+				AuthorId:          "author",
+				Status:            api.PullRequestStatusOPEN,
+				AssignedReviewers: reviewers,
+			}, nil
+		},
+		getUser: func(_ context.Context, id string) (api.User, error) {
+			return byID[id], nil
+		},
+		listActiveUsersInTeam: func(context.Context, string) ([]api.User, error) {
+			return team, nil
+		},
+		listActiveUsers: func(_ context.Context, ids []string) ([]api.User, error) {
+			var users []api.User
+			for _, id := range ids {
+				users = append(users, byID[id])
+			}
+			return users, nil
+		},
+		getTeamSettings: mentoringSettings(true, false),
+		replaceReviewer: func(_ context.Context, _, _, newID string) error {
+			replaced = newID
+			return nil
+		},
+	})
+
+	for i := 0; i < 20; i++ {
+		if _, _, err := svc.ReassignReviewer(context.Background(), "pr-1", "s1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if replaced != "s2" {
+			t.Fatalf("the only senior must be replaced by a senior, got %s", replaced)
+		}
+	}
+
+	reviewers = []string{"s1", "s2"}
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		if _, _, err := svc.ReassignReviewer(context.Background(), "pr-1", "s1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[replaced] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("with another senior left any member may replace s1, got only %v", seen)
+	}
+
+	team = team[:4]
+	reviewers = []string{"s1", "m1"}
+	_, _, err := svc.ReassignReviewer(context.Background(), "pr-1", "s1")
 	assertServiceErrorCode(t, err, api.NOCANDIDATE)
 }
 
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+const (
+	defaultReviewersCount  = 2
+	maxReviewersCount      = 10
+	defaultDiversityWindow = 5
+	maxDiversityWindow     = 100
+)
+
+func (s *Service) GetTeamSettings(ctx context.Context, teamName string) (api.TeamSettings, error) {
+	settings, err := s.teamSettings(ctx, teamName)
+	if err != nil {
+		switch err {
+		case repo.ErrNotFound:
+			return api.TeamSettings{}, NewError(api.NOTFOUND, "team not found")
+		}
+		return api.TeamSettings{}, err
+	}
+	return settings, nil
+}
+
+// SetTeamSettings updates the team's settings. Fields left out keep their
+// stored values. A nil MaxOpenReviews keeps the stored limit too, unless
+// clearMaxOpenReviews is set, which removes it.
+func (s *Service) SetTeamSettings(ctx context.Context, settings api.TeamSettings, clearMaxOpenReviews bool) (api.TeamSettings, error) {
+	if _, ok := s.selectors[settings.ReviewerStrategy]; !ok {
+		return api.TeamSettings{}, NewError(api.BADREQUEST, fmt.Sprintf("unknown reviewer strategy %q", settings.ReviewerStrategy))
+	}
+	if settings.ReviewerWeights != nil {
+		for userID, w := range *settings.ReviewerWeights {
+			if w < 0 {
+				return api.TeamSettings{}, NewError(api.BADREQUEST, fmt.Sprintf("negative weight for %s", userID))
+			}
+		}
+	}
+	if settings.ReviewersCount != nil {
+		if err := validateReviewersCount(*settings.ReviewersCount); err != nil {
+			return api.TeamSettings{}, err
+		}
+	}
+	if settings.RequiredApprovals != nil {
+		if n := *settings.RequiredApprovals; n < 0 || n > maxReviewersCount {
+			return api.TeamSettings{}, NewError(api.BADREQUEST, fmt.Sprintf("required_approvals must be between 0 and %d", maxReviewersCount))
+		}
+	}
+	if err := validateMaxOpenReviews(settings.MaxOpenReviews); err != nil {
+		return api.TeamSettings{}, err
+	}
+	if settings.DiversityWindow != nil {
+		if n := *settings.DiversityWindow; n < 1 || n > maxDiversityWindow {
+			return api.TeamSettings{}, NewError(api.BADREQUEST, fmt.Sprintf("diversity_window must be between 1 and %d", maxDiversityWindow))
+		}
+	}
+	if settings.FallbackTeams != nil {
+		seen := make(map[string]struct{}, len(*settings.FallbackTeams))
+		for _, name := range *settings.FallbackTeams {
+			if name == settings.TeamName {
+				return api.TeamSettings{}, NewError(api.BADREQUEST, "a team cannot be its own fallback team")
+			}
+			if _, dup := seen[name]; dup {
+				return api.TeamSettings{}, NewError(api.BADREQUEST, fmt.Sprintf("fallback team %s is listed twice", name))
+			}
+			seen[name] = struct{}{}
+		}
+	}
+
+	stored, err := s.repo.GetTeamSettings(ctx, settings.TeamName)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.TeamSettings{}, NewError(api.NOTFOUND, "team not found")
+		}
+		return api.TeamSettings{}, err
+	}
+	keepStoredSettings(&settings, stored)
+	if settings.MaxOpenReviews == nil && !clearMaxOpenReviews {
+		settings.MaxOpenReviews = stored.MaxOpenReviews
+	}
+	applySettingsDefaults(&settings)
+
+	saved, err := s.repo.UpsertTeamSettings(ctx, settings)
+	if err != nil {
+		switch err {
+		case repo.ErrNotFound:
+			return api.TeamSettings{}, NewError(api.NOTFOUND, "team not found")
+		case repo.ErrFallbackNotFound:
+			return api.TeamSettings{}, NewError(api.NOTFOUND, "fallback team not found")
+		}
+		return api.TeamSettings{}, err
+	}
+	return saved, nil
+}
+
+func (s *Service) teamSettings(ctx context.Context, teamName string) (api.TeamSettings, error) {
+	settings, err := s.repo.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		return api.TeamSettings{}, err
+	}
+	if settings.ReviewerStrategy == "" {
+		settings.ReviewerStrategy = s.defaultStrategy
+	}
+	applySettingsDefaults(&settings)
+	return settings, nil
+}
+
+// authorSettings returns the settings of the author's team, or the
+// defaults when the author no longer belongs to any team.
+func (s *Service) authorSettings(ctx context.Context, author api.User) (api.TeamSettings, error) {
+	if author.TeamName == "" {
+		settings := api.TeamSettings{ReviewerStrategy: s.defaultStrategy}
+		applySettingsDefaults(&settings)
+		return settings, nil
+	}
+	return s.teamSettings(ctx, author.TeamName)
+}
+
+// keepStoredSettings fills the fields settings leaves out from stored.
+func keepStoredSettings(settings *api.TeamSettings, stored api.TeamSettings) {
+	if settings.ReviewerWeights == nil {
+		settings.ReviewerWeights = stored.ReviewerWeights
+	}
+	if settings.ReviewersCount == nil {
+		settings.ReviewersCount = stored.ReviewersCount
+	}
+	if settings.RequiredApprovals == nil {
+		settings.RequiredApprovals = stored.RequiredApprovals
+	}
+	if settings.BlockOnChangesRequested == nil {
+		settings.BlockOnChangesRequested = stored.BlockOnChangesRequested
+	}
+	if settings.FallbackTeams == nil {
+		settings.FallbackTeams = stored.FallbackTeams
+	}
+	if settings.RequireSenior == nil {
+		settings.RequireSenior = stored.RequireSenior
+	}
+	if settings.JuniorShadow == nil {
+		settings.JuniorShadow = stored.JuniorShadow
+	}
+	if settings.DiversityWindow == nil {
+		settings.DiversityWindow = stored.DiversityWindow
+	}
+}
+
+func applySettingsDefaults(settings *api.TeamSettings) {
+	if settings.ReviewerWeights == nil {
+		settings.ReviewerWeights = &map[string]int{}
+	}
+	if settings.ReviewersCount == nil {
+		n := defaultReviewersCount
+		settings.ReviewersCount = &n
+	}
+	if settings.RequiredApprovals == nil {
+		n := 0
+		settings.RequiredApprovals = &n
+	}
+	if settings.BlockOnChangesRequested == nil {
+		block := false
+		settings.BlockOnChangesRequested = &block
+	}
+	if settings.FallbackTeams == nil {
+		settings.FallbackTeams = &[]string{}
+	}
+	if settings.RequireSenior == nil {
+		require := false
+		settings.RequireSenior = &require
+	}
+	if settings.JuniorShadow == nil {
+		shadow := false
+		settings.JuniorShadow = &shadow
+	}
+	if settings.DiversityWindow == nil {
+		n := defaultDiversityWindow
+		settings.DiversityWindow = &n
+	}
+}
+
+func validateReviewersCount(n int) error {
+	if n < 0 || n > maxReviewersCount {
+		return NewError(api.BADREQUEST, fmt.Sprintf("reviewers_count must be between 0 and %d", maxReviewersCount))
+	}
+	return nil
+}
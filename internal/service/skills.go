@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// SetUserTags replaces the user's skill tags.
+func (s *Service) SetUserTags(ctx context.Context, userID string, tags []string) (api.User, error) {
+	tags, err := normalizeTags(tags, "tags")
+	if err != nil {
+		return api.User{}, err
+	}
+	user, err := s.repo.SetUserTags(ctx, userID, tags)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.User{}, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.User{}, err
+	}
+	return user, nil
+}
+
+// skillOverlap counts, for each candidate, how many labels match their
+// tags.
+func (s *Service) skillOverlap(ctx context.Context, candidates, labels []string) (map[string]int, error) {
+	users, err := s.repo.ListActiveUsers(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(labels))
+	for _, l := range labels {
+		wanted[l] = struct{}{}
+	}
+	overlap := make(map[string]int, len(users))
+	for _, u := range users {
+		if u.Tags == nil {
+			continue
+		}
+		for _, tag := range *u.Tags {
+			if _, ok := wanted[tag]; ok {
+				overlap[u.UserId]++
+			}
+		}
+	}
+	return overlap, nil
+}
+
+// normalizeTags lower-cases and trims tags and drops duplicates, so "Go"
+// and "go " count as the same skill. field names the tags in errors.
+func normalizeTags(tags []string, field string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, NewError(api.BADREQUEST, fmt.Sprintf("%s must not contain empty values", field))
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out, nil
+}
+
+// prepareMembers validates team members before they are stored and
+// normalizes their tags in place.
+func prepareMembers(members []api.TeamMember) error {
+	for i := range members {
+		m := &members[i]
+		if err := validateMaxOpenReviews(m.MaxOpenReviews); err != nil {
+			return err
+		}
+		if m.Level != nil {
+			if err := validateLevel(*m.Level); err != nil {
+				return err
+			}
+		}
+		if m.Tags != nil {
+			tags, err := normalizeTags(*m.Tags, "tags")
+			if err != nil {
+				return err
+			}
+			m.Tags = &tags
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pr-reviewer/internal/api"
+	"pr-reviewer/internal/repo"
+)
+
+// PullRequestAction is what a Git host reports happened to a pull request.
+type PullRequestAction string
+
+const (
+	PullRequestOpened           PullRequestAction = "opened"
+	PullRequestMerged           PullRequestAction = "merged"
+	PullRequestClosed           PullRequestAction = "closed"
+	PullRequestReopened         PullRequestAction = "reopened"
+	PullRequestReadyForReview   PullRequestAction = "ready_for_review"
+	PullRequestConvertedToDraft PullRequestAction = "converted_to_draft"
+)
+
+// PullRequestEvent is a Git host webhook translated into this service's
+// terms. AuthorLogin, Title, Draft and Labels are only used when the pull
+// request is opened.
+type PullRequestEvent struct {
+	Provider      api.GitProvider
+	Action        PullRequestAction
+	PullRequestID string
+	Title         string
+	AuthorLogin   string
+	Draft         bool
+	Labels        []string
+}
+
+// ApplyPullRequestEvent mirrors a Git host event onto the pull request.
+// Every transition is idempotent, so redelivered events change nothing.
+// Events that can't be applied, such as a pull request opened by an
+// unmapped login or one the service doesn't track, are reported as ignored
+// rather than failed.
+func (s *Service) ApplyPullRequestEvent(ctx context.Context, ev PullRequestEvent) (api.WebhookResult, error) {
+	id := ev.PullRequestID
+	result := api.WebhookResult{PullRequestId: &id}
+
+	var pr api.PullRequest
+	var warnings []api.Warning
+	var err error
+	switch ev.Action {
+	case PullRequestOpened:
+		authorID, lookupErr := s.repo.ExternalLoginUser(ctx, ev.Provider, normalizeLogin(ev.AuthorLogin))
+		if lookupErr == repo.ErrNotFound {
+			return ignored(result, fmt.Sprintf("%s login %s is not mapped to a user", ev.Provider, ev.AuthorLogin)), nil
+		}
+		if lookupErr != nil {
+			return api.WebhookResult{}, lookupErr
+		}
+		draft := ev.Draft
+		labels := ev.Labels
+		pr, warnings, err = s.createPullRequest(ctx, api.PostPullRequestCreateJSONBody{
+			PullRequestId:   ev.PullRequestID,
+			PullRequestName: ev.Title,
+			AuthorId:        authorID,
+			Draft:           &draft,
+			Labels:          &labels,
+		}, ev.Provider)
+	case PullRequestMerged:
+		// The host has merged it already, so approvals don't matter here,
+		// but nobody overrode them either.
+		pr, err = s.mergePullRequest(ctx, ev.PullRequestID, false, false)
+	case PullRequestClosed:
+		pr, err = s.ClosePullRequest(ctx, ev.PullRequestID)
+	case PullRequestReopened:
+		pr, warnings, err = s.ReopenPullRequest(ctx, ev.PullRequestID)
+	case PullRequestReadyForReview:
+		pr, warnings, err = s.MarkPullRequestReady(ctx, ev.PullRequestID, nil)
+	case PullRequestConvertedToDraft:
+		pr, err = s.MarkPullRequestDraft(ctx, ev.PullRequestID)
+	default:
+		return ignored(result, fmt.Sprintf("action %s is not handled", ev.Action)), nil
+	}
+
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return ignored(result, svcErr.Msg), nil
+	}
+	if err != nil {
+		return api.WebhookResult{}, err
+	}
+
+	result.Result = api.Applied
+	result.PullRequest = &pr
+	if len(warnings) > 0 {
+		result.Warnings = &warnings
+	}
+	return result, nil
+}
+
+func ignored(result api.WebhookResult, reason string) api.WebhookResult {
+	result.Result = api.Ignored
+	result.Reason = &reason
+	return result
+}
+
+func (s *Service) SetExternalLogin(ctx context.Context, login api.ExternalLogin) (api.ExternalLogin, error) {
+	if err := validateProvider(login.Provider); err != nil {
+		return api.ExternalLogin{}, err
+	}
+	login.Login = normalizeLogin(login.Login)
+	if login.Login == "" {
+		return api.ExternalLogin{}, NewError(api.BADREQUEST, "login must not be empty")
+	}
+
+	saved, err := s.repo.SetExternalLogin(ctx, login)
+	if err != nil {
+		if err == repo.ErrUserNotFound {
+			return api.ExternalLogin{}, NewError(api.NOTFOUND, "user not found")
+		}
+		return api.ExternalLogin{}, err
+	}
+	return saved, nil
+}
+
+func (s *Service) DeleteExternalLogin(ctx context.Context, provider api.GitProvider, login string) (api.ExternalLogin, error) {
+	if err := validateProvider(provider); err != nil {
+		return api.ExternalLogin{}, err
+	}
+	deleted, err := s.repo.DeleteExternalLogin(ctx, provider, normalizeLogin(login))
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return api.ExternalLogin{}, NewError(api.NOTFOUND, "login mapping not found")
+		}
+		return api.ExternalLogin{}, err
+	}
+	return deleted, nil
+}
+
+func (s *Service) ListExternalLogins(ctx context.Context, provider *api.GitProvider) ([]api.ExternalLogin, error) {
+	if provider == nil {
+		return s.repo.ListExternalLogins(ctx, "")
+	}
+	if err := validateProvider(*provider); err != nil {
+		return nil, err
+	}
+	return s.repo.ListExternalLogins(ctx, *provider)
+}
+
+func validateProvider(provider api.GitProvider) error {
+	switch provider {
+	case api.Github, api.Gitlab:
+		return nil
+	}
+	return NewError(api.BADREQUEST, fmt.Sprintf("unknown provider %q", provider))
+}
+
+// normalizeLogin folds case, since Git hosts treat logins
+// case-insensitively.
+func normalizeLogin(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
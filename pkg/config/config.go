@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -9,6 +10,42 @@ type Config struct {
 	Port        string
 	DatabaseURL string
 
+	ReviewerStrategy string
+	// Seed fixes the random source used to pick reviewers; nil seeds it
+	// from the clock.
+	Seed *int64
+	// DeterministicAssignment derives each pull request's reviewers from
+	// a hash of its id.
+	DeterministicAssignment bool
+
+	// GitHubWebhookSecret signs GitHub webhooks; /webhooks/github is
+	// disabled while it is empty.
+	GitHubWebhookSecret string
+	// GitLabWebhookToken is the secret token of GitLab webhooks;
+	// /webhooks/gitlab is disabled while it is empty.
+	GitLabWebhookToken string
+
+	// GitHubToken lets the service request and remove reviewers on GitHub
+	// pull requests it mirrors; nothing is pushed while it is empty.
+	GitHubToken  string
+	GitHubAPIURL string
+	// ReviewerSyncInterval is how often queued reviewer changes are
+	// pushed to Git hosts.
+	ReviewerSyncInterval time.Duration
+
+	// WebhookDeliveryInterval is how often queued events are sent to
+	// webhook subscribers, and WebhookTimeout how long one may take.
+	WebhookDeliveryInterval time.Duration
+	WebhookTimeout          time.Duration
+
+	// OutboxInterval is how often recorded events are dispatched from the
+	// outbox to webhook deliveries and reviewer sync.
+	OutboxInterval time.Duration
+
+	// HandoffInterval is how often scheduled review handoffs for upcoming
+	// unavailability windows are checked.
+	HandoffInterval time.Duration
+
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
@@ -19,6 +56,24 @@ func FromEnv() Config {
 		Port:        getenv("PORT", "8080"),
 		DatabaseURL: getenv("DATABASE_URL", "postgres://postgres:postgres@db:5432/app?sslmode=disable"),
 
+		ReviewerStrategy:        getenv("REVIEWER_STRATEGY", "least_loaded"),
+		Seed:                    parseSeed("SEED"),
+		DeterministicAssignment: parseBool("DETERMINISTIC_ASSIGNMENT", false),
+
+		GitHubWebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabWebhookToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+
+		GitHubToken:          os.Getenv("GITHUB_TOKEN"),
+		GitHubAPIURL:         getenv("GITHUB_API_URL", "https://api.github.com"),
+		ReviewerSyncInterval: parseDuration("REVIEWER_SYNC_INTERVAL", 5*time.Second),
+
+		WebhookDeliveryInterval: parseDuration("WEBHOOK_DELIVERY_INTERVAL", 2*time.Second),
+		WebhookTimeout:          parseDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		OutboxInterval: parseDuration("OUTBOX_DISPATCH_INTERVAL", time.Second),
+
+		HandoffInterval: parseDuration("HANDOFF_INTERVAL", time.Minute),
+
 		ReadTimeout:  parseDuration("SERVER_READ_TIMEOUT", 15*time.Second),
 		WriteTimeout: parseDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
 		IdleTimeout:  parseDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
@@ -40,3 +95,21 @@ func parseDuration(env string, def time.Duration) time.Duration {
 	}
 	return def
 }
+
+func parseSeed(env string) *int64 {
+	if v := os.Getenv(env); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return &n
+		}
+	}
+	return nil
+}
+
+func parseBool(env string, def bool) bool {
+	if v := os.Getenv(env); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}